@@ -0,0 +1,207 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// actionPublished is the action of an event sent when a message is published to a channel
+const actionPublished = "published"
+
+// Channel is a named realtime channel which services can publish messages to and subscribe to
+type Channel struct {
+	Name     string
+	realtime *Realtime
+}
+
+// ChannelMessage is a message published to a channel
+type ChannelMessage struct {
+	ID      string
+	Channel string
+	// SenderID is the id of the user or API key which published the message
+	SenderID  string
+	Timestamp time.Time
+	// Data is the payload as it was published, use Decode to read it into a type of your own
+	Data json.RawMessage
+}
+
+// Decode reads the payload of the message into the target, such as a pointer to a struct with JSON tags
+func (m ChannelMessage) Decode(target interface{}) error {
+	return json.Unmarshal(m.Data, target)
+}
+
+// UnmarshalJSON decodes a message as stored in the history of a channel
+func (m *ChannelMessage) UnmarshalJSON(b []byte) error {
+	var stored struct {
+		ID        string          `json:"id"`
+		SenderID  string          `json:"sender_id"`
+		CreatedAt time.Time       `json:"created_at"`
+		Data      json.RawMessage `json:"data"`
+	}
+	err := json.Unmarshal(b, &stored)
+	if err != nil {
+		return err
+	}
+	*m = ChannelMessage{
+		ID:        stored.ID,
+		SenderID:  stored.SenderID,
+		Timestamp: stored.CreatedAt,
+		Data:      stored.Data,
+	}
+	return nil
+}
+
+// HistoryOption allows the history of a channel to be limited to certain messages
+type HistoryOption func(*historyConfig)
+
+// historyConfig holds the values set by each HistoryOption
+type historyConfig struct {
+	since time.Time
+	until time.Time
+	limit int
+}
+
+// Since limits the history to messages published at or after the time
+func Since(since time.Time) HistoryOption {
+	return func(h *historyConfig) {
+		h.since = since
+	}
+}
+
+// Until limits the history to messages published before the time
+func Until(until time.Time) HistoryOption {
+	return func(h *historyConfig) {
+		h.until = until
+	}
+}
+
+// HistoryLimit sets the maximum number of messages to return, starting from the oldest
+func HistoryLimit(limit int) HistoryOption {
+	return func(h *historyConfig) {
+		h.limit = limit
+	}
+}
+
+// Channel returns the channel with the name, the channel does not need to be created beforehand
+func (r *Realtime) Channel(name string) *Channel {
+	return &Channel{
+		Name:     name,
+		realtime: r,
+	}
+}
+
+// Publish sends the payload to every subscriber of the channel
+// The payload can be any value which marshals into JSON, a json.RawMessage is sent as it is
+func (ch *Channel) Publish(ctx context.Context, payload interface{}) error {
+	data, err := marshal(payload)
+	if err != nil {
+		return err
+	}
+	return ch.realtime.command(ctx, "publish", map[string]interface{}{
+		"channel": ch.Name,
+		"data":    json.RawMessage(data),
+	})
+}
+
+// ChannelSubscription receives the messages published to a channel
+type ChannelSubscription struct {
+	*Subscription
+	// Messages receives each message in the order it was published, it is closed once the subscription ends
+	Messages <-chan ChannelMessage
+}
+
+// Subscribe delivers the messages published to the channel until the context ends
+// Once the context ends it unsubscribes and closes the channel of messages, it is also closed if the realtime connection closes
+// Options such as WithBufferSize and WithOverflowPolicy decide what happens when messages are not read quickly enough, by default the oldest buffered message is dropped
+func (ch *Channel) Subscribe(ctx context.Context, opts ...SubscribeOption) (*ChannelSubscription, error) {
+	s, err := ch.realtime.subscribe(
+		ctx,
+		ch.Name,
+		rtcResource{Type: "channel", Name: ch.Name},
+		[]string{actionPublished},
+		nil,
+		newSubscribeConfig(opts),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(chan ChannelMessage)
+	go func() {
+		defer close(messages)
+		ch.realtime.relay(ctx, s, func(received rtcEventData) bool {
+			select {
+			case messages <- newChannelMessage(received):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return &ChannelSubscription{
+		Subscription: &Subscription{s: s},
+		Messages:     messages,
+	}, nil
+}
+
+// newChannelMessage converts an event from the realtime service into a ChannelMessage
+func newChannelMessage(received rtcEventData) ChannelMessage {
+	return ChannelMessage{
+		ID:        received.ID,
+		Channel:   received.Resource.Name,
+		SenderID:  received.Modifier.ID,
+		Timestamp: received.Timestamp,
+		Data:      received.Data,
+	}
+}
+
+// History fetches the messages stored for the channel, oldest first, the channel must be created with history enabled
+// Messages are returned in the same form as Subscribe, so a consumer can backfill from the history before reading live messages
+func (ch *Channel) History(ctx context.Context, opts ...HistoryOption) ([]ChannelMessage, error) {
+	config := historyConfig{}
+	for _, o := range opts {
+		o(&config)
+	}
+
+	var condition *Condition
+	if !config.since.IsZero() {
+		condition = Field("created_at").IsEqualOrGreaterThan(config.since)
+	}
+	if !config.until.IsZero() {
+		before := Field("created_at").IsLessThan(config.until)
+		if condition == nil {
+			condition = before
+		} else {
+			condition = condition.And(before)
+		}
+	}
+	queryOpts := []QueryOption{SortAsc("created_at")}
+	if condition != nil {
+		queryOpts = append(queryOpts, Where(condition))
+	}
+	if config.limit > 0 {
+		queryOpts = append(queryOpts, Limit(config.limit))
+	}
+	query, err := buildQuery(queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	client := ch.realtime.client
+	var messages []ChannelMessage
+	err = client.get(
+		fmt.Sprintf("%v/channel/%v%v", client.projectURL, ch.Name, query),
+		&messages,
+		setContext(ctx),
+		addToken(client.GetToken().Access),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		messages[i].Channel = ch.Name
+	}
+	return messages, nil
+}
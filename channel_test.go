@@ -0,0 +1,131 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelPublish(t *testing.T) {
+	published := make(chan map[string]json.RawMessage, 1)
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		var arguments map[string]json.RawMessage
+		assert.Equal(t, "publish", readCommand(t, conn, &arguments))
+		published <- arguments
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.NewRealtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	err = realtime.Channel("notifications").Publish(context.Background(), map[string]string{"title": "hello"})
+	assert.NoError(t, err)
+	select {
+	case arguments := <-published:
+		assert.JSONEq(t, `"notifications"`, string(arguments["channel"]))
+		assert.JSONEq(t, `{"title":"hello"}`, string(arguments["data"]))
+	case <-time.After(2 * time.Second):
+		t.Fatal("Nothing was published")
+	}
+}
+
+func TestChannelSubscribe(t *testing.T) {
+	unsubscribed := make(chan rtcSubscription, 1)
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.Equal(t, rtcSubscription{Action: []string{"published"}, Namespace: "notifications"}, arguments)
+
+		writeEvent(t, conn, `{"id":"m0","action":"published","resource":{"type":"channel","name":"other"},"data":{}}`)
+		writeEvent(t, conn, `{"id":"m1","action":"published","resource":{"type":"channel","name":"notifications"},"modifier":{"id":"service"},"timestamp":"2020-10-01T12:00:00Z","data":{"title":"hello"}}`)
+
+		assert.Equal(t, "unsubscribe", readCommand(t, conn, &arguments))
+		unsubscribed <- arguments
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.NewRealtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := realtime.Channel("notifications").Subscribe(ctx)
+	assert.NoError(t, err)
+
+	select {
+	case message := <-subscription.Messages:
+		assert.Equal(t, "m1", message.ID)
+		assert.Equal(t, "notifications", message.Channel)
+		assert.Equal(t, "service", message.SenderID)
+		assert.Equal(t, time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC), message.Timestamp)
+		var payload struct {
+			Title string `json:"title"`
+		}
+		assert.NoError(t, message.Decode(&payload))
+		assert.Equal(t, "hello", payload.Title)
+		assert.JSONEq(t, `{"title":"hello"}`, string(message.Data))
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message was received")
+	}
+
+	cancel()
+	select {
+	case arguments := <-unsubscribed:
+		assert.Equal(t, "notifications", arguments.Namespace)
+	case <-time.After(2 * time.Second):
+		t.Fatal("The subscription was not removed")
+	}
+	_, open := <-subscription.Messages
+	assert.False(t, open)
+}
+
+func TestChannelHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/channel/notifications", req.URL.Path)
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, "Bearer yourAccessToken", req.Header.Get("Authorization"))
+		assert.Equal(t, `[{"field":"created_at"},"\u003e=","2020-10-01T00:00:00Z","and",{"field":"created_at"},"\u003c","2020-10-02T00:00:00Z"]`, req.URL.Query().Get("cond"))
+		assert.Equal(t, `[{"asc":["created_at"]}]`, req.URL.Query().Get("order"))
+		assert.Equal(t, `{"limit":2}`, req.URL.Query().Get("range"))
+		// Send response to be tested
+		rw.Write([]byte(`[
+			{"id":"m1","sender_id":"service","created_at":"2020-10-01T12:00:00Z","data":{"title":"hello"}},
+			{"id":"m2","sender_id":"service","created_at":"2020-10-01T13:00:00Z","data":{"title":"world"}}
+		]`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	// History does not use the websocket, so a realtime which is not connected is enough
+	realtime := &Realtime{client: client}
+
+	messages, err := realtime.Channel("notifications").History(
+		context.Background(),
+		Since(time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)),
+		Until(time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)),
+		HistoryLimit(2),
+	)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "m1", messages[0].ID)
+	assert.Equal(t, "notifications", messages[0].Channel)
+	assert.Equal(t, "service", messages[0].SenderID)
+	assert.Equal(t, time.Date(2020, 10, 1, 13, 0, 0, 0, time.UTC), messages[1].Timestamp)
+	var payload struct {
+		Title string `json:"title"`
+	}
+	assert.NoError(t, messages[1].Decode(&payload))
+	assert.Equal(t, "world", payload.Title)
+}
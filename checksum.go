@@ -0,0 +1,124 @@
+package jexiasdkgo
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// ChecksumField is the custom field holding the hex encoded SHA-256 of a file, set by every upload
+const ChecksumField = "checksum"
+
+// sniffLength is the number of bytes used by http.DetectContentType
+const sniffLength = 512
+
+// ErrChecksumMismatch is returned when a downloaded file does not match the checksum stored with it, compare using errors.Is
+var ErrChecksumMismatch = &Error{
+	ID:        "e011",
+	Message:   "Checksum of the downloaded file does not match",
+	Origin:    Internal,
+	Temporary: false,
+}
+
+// WithContentType sets the content type of the file rather than it being detected from its contents
+func WithContentType(contentType string) UploadOption {
+	return func(u *uploadConfig) {
+		u.contentType = contentType
+	}
+}
+
+// detectContentType works out the content type of a file from its first bytes without consuming them
+// The returned reader must be used in place of the file as it holds the bytes which were looked at
+func detectContentType(filename string, file io.Reader) (string, io.Reader) {
+	buffered := bufio.NewReaderSize(file, sniffLength)
+	// Any error here is returned again by the first read of the buffered reader
+	head, _ := buffered.Peek(sniffLength)
+	contentType := http.DetectContentType(head)
+	// Text formats such as CSS and JavaScript can only be told apart by their extension
+	if contentType == "application/octet-stream" || contentType == "text/plain; charset=utf-8" {
+		if byExtension := mime.TypeByExtension(filepath.Ext(filename)); byExtension != "" {
+			contentType = byExtension
+		}
+	}
+	return contentType, buffered
+}
+
+// hashReader computes the SHA-256 of everything read through it
+type hashReader struct {
+	reader io.Reader
+	hash   hash.Hash
+}
+
+func newHashReader(reader io.Reader) *hashReader {
+	return &hashReader{
+		reader: reader,
+		hash:   sha256.New(),
+	}
+}
+
+func (h *hashReader) Read(b []byte) (int, error) {
+	n, err := h.reader.Read(b)
+	h.hash.Write(b[:n])
+	return n, err
+}
+
+// Checksum returns the hex encoded SHA-256 of the bytes read so far
+func (h *hashReader) Checksum() string {
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
+
+// verifyingReader checks the SHA-256 of a download once it has been read in full
+// The end of the file is only reported if the checksum matches, otherwise an error matching ErrChecksumMismatch is returned
+type verifyingReader struct {
+	*hashReader
+	body     io.ReadCloser
+	expected string
+}
+
+func newVerifyingReader(body io.ReadCloser, expected string) *verifyingReader {
+	return &verifyingReader{
+		hashReader: newHashReader(body),
+		body:       body,
+		expected:   expected,
+	}
+}
+
+func (v *verifyingReader) Read(b []byte) (int, error) {
+	n, err := v.hashReader.Read(b)
+	if err == io.EOF && v.Checksum() != v.expected {
+		return n, &Error{
+			ID:        ErrChecksumMismatch.ID,
+			Message:   fmt.Sprintf("%v: expected %v, got %v", ErrChecksumMismatch.Message, v.expected, v.Checksum()),
+			Origin:    Internal,
+			Temporary: false,
+		}
+	}
+	return n, err
+}
+
+func (v *verifyingReader) Close() error {
+	return v.body.Close()
+}
+
+// fileChecksum returns the hex encoded SHA-256 of the file at the path
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := newHashReader(file)
+	_, err = io.Copy(ioutil.Discard, hash)
+	if err != nil {
+		return "", err
+	}
+	return hash.Checksum(), nil
+}
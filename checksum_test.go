@@ -0,0 +1,111 @@
+package jexiasdkgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectContentType(t *testing.T) {
+	contentType, reader := detectContentType("page.html", strings.NewReader("<!DOCTYPE html><html></html>"))
+	assert.Equal(t, "text/html; charset=utf-8", contentType)
+	// The bytes looked at must still be read from the returned reader
+	content, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "<!DOCTYPE html><html></html>", string(content))
+
+	contentType, _ = detectContentType("site.css", strings.NewReader("body { margin: 0; }"))
+	assert.Equal(t, "text/css; charset=utf-8", contentType)
+
+	contentType, _ = detectContentType("image.png", bytes.NewReader([]byte("\x89PNG\x0D\x0A\x1A\x0A")))
+	assert.Equal(t, "image/png", contentType)
+}
+
+func TestFilesetUploadContentType(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.NoError(t, req.ParseMultipartForm(1024))
+		_, header, err := req.FormFile("file")
+		assert.NoError(t, err)
+		assert.Equal(t, "application/x-custom", header.Header.Get("Content-Type"))
+		rw.Write([]byte(`{"id":"test"}`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	metadata := map[string]interface{}{"owner": "tester"}
+	_, err := fileset.Upload(context.Background(), "hello.txt", strings.NewReader("hello world"), metadata, WithContentType("application/x-custom"))
+	assert.NoError(t, err)
+	// The metadata passed in is not changed by the checksum being added
+	assert.Equal(t, map[string]interface{}{"owner": "tester"}, metadata)
+}
+
+func newChecksumServer(t *testing.T, content, checksum string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/files/hello.txt" {
+			rw.Write([]byte(content))
+			return
+		}
+		rw.Write([]byte(fmt.Sprintf(`[{"id":"test","url":"%v/files/hello.txt","status":"completed","checksum":"%v"}]`, server.URL, checksum)))
+	}))
+	return server
+}
+
+func TestFilesetDownloadVerifiesChecksum(t *testing.T) {
+	server := newChecksumServer(t, "hello world", "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9")
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	var buffer bytes.Buffer
+	_, err := fileset.Download(context.Background(), "test", &buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", buffer.String())
+}
+
+func TestFilesetDownloadChecksumMismatch(t *testing.T) {
+	server := newChecksumServer(t, "hello w0rld", "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9")
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	var buffer bytes.Buffer
+	_, err := fileset.Download(context.Background(), "test", &buffer)
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+
+	// Partial downloads can not be verified so are passed through as is
+	body, err := fileset.Open(context.Background(), "test", FromOffset(6))
+	assert.NoError(t, err)
+	defer body.Close()
+	content, err := ioutil.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "w0rld", string(content))
+}
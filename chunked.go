@@ -0,0 +1,246 @@
+package jexiasdkgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultPartSize is the size in bytes of each part sent by UploadChunked
+	DefaultPartSize = 4 * 1024 * 1024
+	// DefaultPartRetries is the number of times a part is retried after a temporary error
+	DefaultPartRetries = 5
+	// DefaultPartTimeout is the time allowed for each attempt at sending a part
+	DefaultPartTimeout = 5 * time.Minute
+	// partRetryDelay is the delay before the first retry of a part, doubling after each attempt
+	partRetryDelay = 500 * time.Millisecond
+)
+
+// WithPartSize sets the size in bytes of each part sent by UploadChunked, DefaultPartSize is used if it is not positive
+func WithPartSize(size int64) UploadOption {
+	return func(u *uploadConfig) {
+		u.partSize = size
+	}
+}
+
+// WithPartRetries sets the number of times a part is retried after a temporary error
+func WithPartRetries(retries int) UploadOption {
+	return func(u *uploadConfig) {
+		u.partRetries = retries
+	}
+}
+
+// WithPartTimeout sets the time allowed for each attempt at sending a part, this is used instead of the client timeout
+func WithPartTimeout(timeout time.Duration) UploadOption {
+	return func(u *uploadConfig) {
+		u.partTimeout = timeout
+	}
+}
+
+// WithResumeFile sets where the progress of a chunked upload is saved, by default this is the file path with ".upload" appended
+func WithResumeFile(path string) UploadOption {
+	return func(u *uploadConfig) {
+		u.resumeFile = path
+	}
+}
+
+// chunkedUploadState is saved after each part so an interrupted upload can be continued, even after a restart
+type chunkedUploadState struct {
+	Fileset  string    `json:"fileset"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	PartSize int64     `json:"part_size"`
+	RecordID string    `json:"record_id"`
+	// Sent is the number of bytes which have been accepted by the API
+	Sent int64 `json:"sent"`
+}
+
+// loadChunkedUploadState reads the saved state, returning nil if there is none or it is for a different file
+func loadChunkedUploadState(path string, expected chunkedUploadState) *chunkedUploadState {
+	var state chunkedUploadState
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	err = unmarshal(b, &state)
+	if err != nil || state.RecordID == "" {
+		return nil
+	}
+	// A changed file can not be resumed as the parts already sent will not match
+	if state.Fileset != expected.Fileset ||
+		state.Size != expected.Size ||
+		!state.ModTime.Equal(expected.ModTime) ||
+		state.PartSize != expected.PartSize {
+		return nil
+	}
+	return &state
+}
+
+// save writes the state to a temporary file first so a crash while saving never leaves a broken state behind
+func (s *chunkedUploadState) save(path string) error {
+	b, err := marshal(s)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(path+".tmp", b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// UploadChunked uploads the file at the path in parts, retrying each part after temporary errors
+// The progress is saved to a resume file after each part, calling UploadChunked again with the same file continues from the last part sent
+// Each part is sent as a ranged PUT request against the file record, which is created first with the metadata, checksum and total size
+func (f *Fileset) UploadChunked(ctx context.Context, path string, metadata map[string]interface{}, opts ...UploadOption) (*FileRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	config := newUploadConfig(file, opts)
+	if config.resumeFile == "" {
+		config.resumeFile = path + ".upload"
+	}
+
+	expected := chunkedUploadState{
+		Fileset:  f.GetName(),
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		PartSize: config.partSize,
+	}
+	if config.contentType == "" {
+		// The file is read with ReadAt from here on, so the bytes consumed while detecting do not matter
+		config.contentType, _ = detectContentType(info.Name(), file)
+	}
+
+	state := loadChunkedUploadState(config.resumeFile, expected)
+	if state == nil {
+		state = &expected
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return nil, err
+		}
+		record, err := f.createChunkedRecord(ctx, info.Name(), info.Size(), withChecksum(metadata, checksum))
+		if err != nil {
+			return nil, cancelledError(ctx, err)
+		}
+		state.RecordID = record.ID
+		err = state.save(config.resumeFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	progress := newProgressReader(ctx, file, config)
+	progress.sent = state.Sent
+	part := make([]byte, config.partSize)
+	for state.Sent < state.Size {
+		n, err := file.ReadAt(part, state.Sent)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		err = f.uploadPart(ctx, config, state, part[:n])
+		if err != nil {
+			return nil, cancelledError(ctx, err)
+		}
+		state.Sent += int64(n)
+		err = state.save(config.resumeFile)
+		if err != nil {
+			return nil, err
+		}
+		progress.sent = state.Sent
+		progress.report(state.Sent == state.Size)
+	}
+
+	record, err := f.getRecord(ctx, state.RecordID)
+	if err != nil {
+		return nil, cancelledError(ctx, err)
+	}
+	// The upload is finished so there is nothing left to resume
+	os.Remove(config.resumeFile)
+	return record, nil
+}
+
+// createChunkedRecord creates the record of a file without any content, the parts are then sent separately
+func (f *Fileset) createChunkedRecord(ctx context.Context, filename string, size int64, metadata map[string]interface{}) (*FileRecord, error) {
+	var record FileRecord
+	data, err := marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("data", string(data))
+	form.WriteField("name", filename)
+	form.WriteField("size", fmt.Sprint(size))
+	form.Close()
+
+	err = f.GetClient().post(
+		fmt.Sprintf("%v/fs/%v", f.GetClient().projectURL, f.GetName()),
+		&record,
+		setContext(ctx),
+		addToken(f.GetClient().GetToken().Access),
+		setHeader("Content-Type", form.FormDataContentType()),
+		setBody(body.Bytes()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// uploadPart sends a single part, retrying with an increasing delay while the errors are temporary or the request could not be made
+func (f *Fileset) uploadPart(ctx context.Context, config uploadConfig, state *chunkedUploadState, part []byte) error {
+	// The client timeout is replaced by the part timeout, so large parts on slow connections are not cut off
+	client := f.GetClient().untimedHTTP()
+
+	delay := partRetryDelay
+	for attempt := 0; ; attempt++ {
+		partCtx, cancel := context.WithTimeout(ctx, config.partTimeout)
+		req, err := f.GetClient().buildRequest(
+			http.MethodPut,
+			fmt.Sprintf("%v/fs/%v/%v", f.GetClient().projectURL, f.GetName(), state.RecordID),
+			setContext(partCtx),
+			addToken(f.GetClient().GetToken().Access),
+			setHeader("Content-Type", config.contentType),
+			setHeader("Content-Range", fmt.Sprintf("bytes %v-%v/%v", state.Sent, state.Sent+int64(len(part))-1, state.Size)),
+			setBody(part),
+		)
+		if err != nil {
+			cancel()
+			return err
+		}
+		err = f.GetClient().executeRequestWith(client, req, nil)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		e := getNiceError(err, "Error uploading part")
+		// A request which could not be made at all, such as after a connection reset, is worth retrying on a flaky link
+		retryable := e.Temporary || e.ID == "e005"
+		if !retryable || attempt >= config.partRetries || ctx.Err() != nil {
+			return e
+		}
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
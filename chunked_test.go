@@ -0,0 +1,197 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkedServer stores the parts it receives and fails requests for the given ranges the given number of times
+type chunkedServer struct {
+	t        *testing.T
+	mux      sync.Mutex
+	created  int
+	content  []byte
+	failures map[string]int
+	status   int
+	// hangUp closes the connection instead of responding to the failed requests
+	hangUp bool
+}
+
+func (s *chunkedServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	switch {
+	case req.Method == http.MethodPost && req.URL.Path == "/fs/test":
+		assert.NoError(s.t, req.ParseMultipartForm(1024))
+		assert.Equal(s.t, `{"checksum":"b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9","owner":"tester"}`, req.FormValue("data"))
+		assert.Equal(s.t, "11", req.FormValue("size"))
+		s.created++
+		rw.Write([]byte(`{"id":"record","name":"hello.txt","status":"in_progress"}`))
+	case req.Method == http.MethodPut && req.URL.Path == "/fs/test/record":
+		contentRange := req.Header.Get("Content-Range")
+		if s.failures[contentRange] > 0 {
+			s.failures[contentRange]--
+			if s.hangUp {
+				conn, _, err := rw.(http.Hijacker).Hijack()
+				if assert.NoError(s.t, err) {
+					conn.Close()
+				}
+				return
+			}
+			rw.WriteHeader(s.status)
+			if s.status == http.StatusBadRequest {
+				rw.Write([]byte(`[{"request_id":"abc","message":"bad part"}]`))
+			}
+			return
+		}
+		var start, end, total int
+		fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total)
+		assert.Equal(s.t, len(s.content), start)
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.Equal(s.t, end-start+1, len(body))
+		s.content = append(s.content, body...)
+	case req.Method == http.MethodGet && req.URL.Path == "/fs/test":
+		rw.Write([]byte(fmt.Sprintf(`[{"id":"record","name":"hello.txt","size":%v,"status":"completed"}]`, len(s.content))))
+	default:
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeTempFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "jexia")
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "hello.txt")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestFilesetUploadChunked(t *testing.T) {
+	handler := &chunkedServer{t: t, failures: map[string]int{"bytes 4-7/11": 2}, status: http.StatusInternalServerError}
+	// Start a local HTTP server
+	server := httptest.NewServer(handler)
+	// Close the server when test finishes
+	defer server.Close()
+
+	path := writeTempFile(t, "hello world")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	var last UploadProgress
+	record, err := fileset.UploadChunked(
+		context.Background(),
+		path,
+		map[string]interface{}{"owner": "tester"},
+		WithPartSize(4),
+		WithProgress(func(progress UploadProgress) {
+			last = progress
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "record", record.ID)
+	assert.Equal(t, FileStatusCompleted, record.Status)
+	assert.Equal(t, "hello world", string(handler.content))
+	assert.Equal(t, int64(11), last.Sent)
+	assert.Equal(t, int64(11), last.Total)
+
+	// Once finished there should be nothing left to resume
+	_, err = os.Stat(path + ".upload")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFilesetUploadChunkedResume(t *testing.T) {
+	handler := &chunkedServer{t: t, failures: map[string]int{"bytes 4-7/11": 1}, status: http.StatusBadRequest}
+	// Start a local HTTP server
+	server := httptest.NewServer(handler)
+	// Close the server when test finishes
+	defer server.Close()
+
+	path := writeTempFile(t, "hello world")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	// The API error is not temporary, so the upload stops after the first part
+	record, err := fileset.UploadChunked(context.Background(), path, map[string]interface{}{"owner": "tester"}, WithPartSize(4))
+	assert.Nil(t, record)
+	assert.Equal(t, "abc", err.(*Error).ID)
+	assert.Equal(t, "hell", string(handler.content))
+
+	state := loadChunkedUploadState(path+".upload", chunkedUploadState{})
+	assert.Nil(t, state, "state should not match a different file")
+	b, err := ioutil.ReadFile(path + ".upload")
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"sent":4`)
+
+	// Calling again continues from the saved state without creating another record
+	record, err = fileset.UploadChunked(context.Background(), path, map[string]interface{}{"owner": "tester"}, WithPartSize(4))
+	assert.NoError(t, err)
+	assert.Equal(t, "record", record.ID)
+	assert.Equal(t, 1, handler.created)
+	assert.Equal(t, "hello world", string(handler.content))
+}
+
+func TestFilesetUploadChunkedInvalidPartSize(t *testing.T) {
+	for _, size := range []int64{0, -1} {
+		handler := &chunkedServer{t: t}
+		// Start a local HTTP server
+		server := httptest.NewServer(handler)
+
+		path := writeTempFile(t, "hello world")
+		client := NewClient(
+			"projectID",
+			"projectZone",
+			SetProjectURL(server.URL),
+		)
+
+		// The default part size is used, so the file is sent in a single part
+		record, err := client.GetFileset("test").UploadChunked(context.Background(), path, map[string]interface{}{"owner": "tester"}, WithPartSize(size))
+		assert.NoError(t, err)
+		assert.Equal(t, "record", record.ID)
+		assert.Equal(t, "hello world", string(handler.content))
+
+		server.Close()
+		os.RemoveAll(filepath.Dir(path))
+	}
+}
+
+func TestFilesetUploadChunkedConnectionDropped(t *testing.T) {
+	handler := &chunkedServer{t: t, failures: map[string]int{"bytes 4-7/11": 2}, hangUp: true}
+	// Start a local HTTP server
+	server := httptest.NewServer(handler)
+	// Close the server when test finishes
+	defer server.Close()
+
+	path := writeTempFile(t, "hello world")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+
+	record, err := client.GetFileset("test").UploadChunked(context.Background(), path, map[string]interface{}{"owner": "tester"}, WithPartSize(4))
+	assert.NoError(t, err)
+	assert.Equal(t, "record", record.ID)
+	assert.Equal(t, "hello world", string(handler.content))
+}
@@ -22,7 +22,17 @@ type Client struct {
 	tokenRequest interface{}
 	http         *http.Client
 	abortRefresh chan bool
+	refreshing   sync.WaitGroup
 	mux          sync.Mutex
+	// refreshListeners are called with the new token after each RefreshToken, such as by a Realtime connection
+	refreshListeners map[*func(Token)]bool
+	// realtime is the connection shared by every subscription, it is guarded by realtimeMux as dialing takes mux
+	realtime        *Realtime
+	realtimeOptions []RealtimeOption
+	// realtimeUsers counts the watches using the shared connection, it is closed once none are left unless realtimeKept is set
+	realtimeUsers int
+	realtimeKept  bool
+	realtimeMux   sync.Mutex
 }
 
 // APKTokenRequest is the JSON data sent to the /auth endpoint when authenticating with the API key
@@ -182,17 +192,14 @@ func (c *Client) UseUMSToken(email, password string) error {
 
 // RefreshToken triggers a token refresh once called
 func (c *Client) RefreshToken() {
-	var newToken Token
-	var e Error
-	token := c.GetToken()
-	payload, _ := marshal(token)
-	err := c.post(fmt.Sprintf("%v/auth/refresh", c.projectURL), &newToken, setBody(payload), addToken(token.Access))
+	err := c.refreshToken()
+	if err == nil {
+		return
+	}
 
 	// Check what error we get, and if it's temporary
-	if err != nil {
-		e = *getNiceError(err, "Error refreshing token")
-		fmt.Println(e.Error())
-	}
+	e := getNiceError(err, "Error refreshing token")
+	fmt.Println(e.Error())
 
 	// If temporary, try again as the connection could have been the issue,
 	if e.Temporary {
@@ -201,16 +208,63 @@ func (c *Client) RefreshToken() {
 		return
 	}
 
+	// The request was not successful, we tried to get a token but there was a serious error
+	log.Fatal("Error refreshing token, there was a unknown, presumed serious error\n")
+}
+
+// refreshToken exchanges the refresh token for new tokens, returning an error rather than exiting when it fails
+func (c *Client) refreshToken() error {
+	var newToken Token
+	token := c.GetToken()
+	payload, _ := marshal(token)
+	err := c.post(fmt.Sprintf("%v/auth/refresh", c.projectURL), &newToken, setBody(payload), addToken(token.Access))
+	if err != nil {
+		return err
+	}
 	if newToken == (Token{}) {
-		// The request was not successful, we tried to get a token but there was a serious error
-		log.Fatal("Error refreshing token, there was a unknown, presumed serious error\n")
-		return
+		return &Error{
+			ID:        "e016",
+			Message:   "Token refresh did not return a token",
+			Origin:    Internal,
+			Temporary: false,
+		}
 	}
 
 	// Pass the new tokens over to the existing, ensuring that the lifetime is not changed
 	token.Access = newToken.Access
 	token.Refresh = newToken.Refresh
 	c.SetToken(token)
+	c.notifyRefreshListeners(token)
+	return nil
+}
+
+// onTokenRefresh calls the listener with the new token after each RefreshToken, until the returned function is called
+func (c *Client) onTokenRefresh(listener func(Token)) (remove func()) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.refreshListeners == nil {
+		c.refreshListeners = make(map[*func(Token)]bool)
+	}
+	key := &listener
+	c.refreshListeners[key] = true
+	return func() {
+		c.mux.Lock()
+		delete(c.refreshListeners, key)
+		c.mux.Unlock()
+	}
+}
+
+// notifyRefreshListeners passes the new token to each listener added by onTokenRefresh
+func (c *Client) notifyRefreshListeners(token Token) {
+	c.mux.Lock()
+	listeners := make([]func(Token), 0, len(c.refreshListeners))
+	for listener := range c.refreshListeners {
+		listeners = append(listeners, *listener)
+	}
+	c.mux.Unlock()
+	for _, listener := range listeners {
+		listener(token)
+	}
 }
 
 // AutoRefreshToken sets the token to refresh at a certain interval based on token lifetime
@@ -220,9 +274,19 @@ func (c *Client) AutoRefreshToken() {
 	c.newRefreshCycle()
 }
 
+// StopAutoRefreshToken stops the refresh cycle started by AutoRefreshToken, waiting for any refresh in progress to finish
+func (c *Client) StopAutoRefreshToken() {
+	close(c.abortRefresh)
+	c.refreshing.Wait()
+	// Re-open channel so the cycle can be started again
+	c.abortRefresh = make(chan bool)
+}
+
 // TODO: Ensure that this new duration is set immediately and not after the current loop
 func (c *Client) newRefreshCycle() {
+	c.refreshing.Add(1)
 	go func() {
+		defer c.refreshing.Done()
 		// start a timer counting down from the token lifetime
 		lifeLeft := time.NewTimer(c.GetToken().Lifetime)
 
@@ -231,6 +295,12 @@ func (c *Client) newRefreshCycle() {
 			select {
 			// triggered when the abortRefresh channel is closed
 			case <-lifeLeft.C:
+				// an abort takes priority when both are ready, otherwise a stopped cycle may refresh once more
+				select {
+				case <-c.abortRefresh:
+					break refreshLoop
+				default:
+				}
 				// refreshes the token and calls another timer
 				c.RefreshToken()
 				lifeLeft = time.NewTimer(c.GetToken().Lifetime)
@@ -18,7 +18,7 @@ func TestSetHTTPClient(t *testing.T) {
 		"projectID",
 		"projectZone",
 	)
-	httpClient = &http.Client{nil, nil, nil, 2 * time.Microsecond}
+	httpClient = &http.Client{Timeout: 2 * time.Microsecond}
 
 	option := SetHTTPClient(httpClient)
 
@@ -571,6 +571,8 @@ func TestAutoRefreshToken(t *testing.T) {
 	// Delay so the refresh cycle has time to loop, connect to the server and receive a response.
 	time.Sleep(3 * time.Millisecond)
 	close(client.abortRefresh)
+	// Wait for any refresh in progress so it does not reach the server after it is closed
+	client.refreshing.Wait()
 
 	assert.Equal(t, "yourNewAccessToken", client.GetToken().Access)
 	assert.Equal(t, "yourNewRefreshToken", client.GetToken().Refresh)
@@ -629,6 +631,8 @@ func TestNewRefreshCycle(t *testing.T) {
 	// Delay so the refresh cycle has time to loop, connect to the server and receive a response.
 	time.Sleep(3 * time.Millisecond)
 	close(client.abortRefresh)
+	// Wait for any refresh in progress so it does not reach the server after it is closed
+	client.refreshing.Wait()
 
 	assert.Equal(t, "yourNewAccessToken", client.GetToken().Access)
 	assert.Equal(t, "yourNewRefreshToken", client.GetToken().Refresh)
@@ -1,6 +1,7 @@
 package jexiasdkgo
 
 import (
+	"context"
 	"fmt"
 	"sync"
 )
@@ -59,10 +60,21 @@ func (d *Dataset) Insert(dataArray []interface{}) ([]interface{}, error) {
 
 // Select allows you to select the data from a dataset
 // You should pass an array of types you are expecting to receive: *[]interface{}
-func (d *Dataset) Select(target interface{}) error {
-	err := d.GetClient().get(
-		fmt.Sprintf("%v/ds/%v", d.GetClient().projectURL, d.GetName()),
+// Options such as Where, SortAsc and Limit can be passed to filter, sort and page the data
+func (d *Dataset) Select(target interface{}, opts ...QueryOption) error {
+	return d.SelectContext(context.Background(), target, opts...)
+}
+
+// SelectContext is the same as Select, the request is cancelled if the context ends first
+func (d *Dataset) SelectContext(ctx context.Context, target interface{}, opts ...QueryOption) error {
+	query, err := buildQuery(opts)
+	if err != nil {
+		return err
+	}
+	err = d.GetClient().get(
+		fmt.Sprintf("%v/ds/%v%v", d.GetClient().projectURL, d.GetName(), query),
 		&target,
+		setContext(ctx),
 		addToken(d.GetClient().GetToken().Access),
 	)
 	if err != nil {
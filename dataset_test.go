@@ -195,3 +195,30 @@ func TestDatasetInsert(t *testing.T) {
 	}
 	assert.Equal(t, expStruct, actualStruct)
 }
+
+func TestDatasetSelectWithOptions(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/ds/test", req.URL.Path)
+		assert.Equal(t, `[{"field":"@name"},"=","tabletop"]`, req.URL.Query().Get("cond"))
+		assert.Equal(t, `[{"desc":["created_at"]}]`, req.URL.Query().Get("order"))
+		assert.Equal(t, `{"limit":1,"offset":1}`, req.URL.Query().Get("range"))
+		// Send response to be tested
+		rw.Write([]byte(`[{"id":"5d7b907c-06bd-41e3-a113-addc230635e1","@name":"tabletop"}]`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	dataset := client.GetDataset("test")
+
+	var data []map[string]interface{}
+	err := dataset.Select(&data, Where(Field("@name").IsEqualTo("tabletop")), SortDesc("created_at"), Limit(1), Offset(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": "5d7b907c-06bd-41e3-a113-addc230635e1", "@name": "tabletop"}}, data)
+}
@@ -18,6 +18,8 @@ type Error struct {
 	Message   string `json:"message"`
 	Origin    string `json:"origin"`
 	Temporary bool   `json:"temporary"`
+	// Status is the http status code of the response the error came from, or 0 if there was no response
+	Status int `json:"-"`
 }
 
 func (e *Error) Error() string {
@@ -28,6 +30,12 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%v endpoint error: %v (Origin: %v) (ID: %v)", temp, e.Message, e.Origin, e.ID)
 }
 
+// Is allows errors to be compared by their ID using errors.Is, such as errors.Is(err, ErrUploadCancelled)
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.ID == e.ID
+}
+
 // checkForAPIError is an internal function wrapper for returning a more useful API error
 func checkForAPIError(response *http.Response) error {
 	// Success is indicated with 2xx status codes:
@@ -60,6 +68,7 @@ func checkForAPIError(response *http.Response) error {
 			Message:   APIErr[0].Message,
 			Origin:    API,
 			Temporary: false,
+			Status:    response.StatusCode,
 		}
 	}
 	// Such unknown error may allow us to retry, it could be due to a network connection drop etc.
@@ -68,6 +77,7 @@ func checkForAPIError(response *http.Response) error {
 		Message:   fmt.Errorf("Unknown error, error does not match predefined parameters, presumed failed call").Error(),
 		Origin:    Internal,
 		Temporary: true,
+		Status:    response.StatusCode,
 	}
 }
 
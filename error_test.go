@@ -2,6 +2,8 @@ package jexiasdkgo
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -50,3 +52,10 @@ func TestCheckErrorOnSuccessfulCall(t *testing.T) {
 	err = checkForAPIError(response)
 	assert.Equal(t, err, nil)
 }
+
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &Error{ID: "e009", Message: "Upload cancelled: context canceled"})
+	assert.True(t, errors.Is(err, ErrUploadCancelled))
+	assert.False(t, errors.Is(err, &Error{ID: "e001"}))
+	assert.False(t, errors.Is(err, fmt.Errorf("e009")))
+}
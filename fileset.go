@@ -0,0 +1,366 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FileStatusInProgress is the status of a file that is still being uploaded or processed
+	FileStatusInProgress = "in_progress"
+	// FileStatusCompleted is the status of a file that has been stored and can be downloaded
+	FileStatusCompleted = "completed"
+	// FileStatusFailed is the status of a file that could not be stored
+	FileStatusFailed = "failed"
+)
+
+// Fileset a struct containing the name of the fileset and the client memory pointer
+// As with datasets, the client is a memory pointer so token refreshes will still work
+type Fileset struct {
+	Name   string
+	Client *Client
+	mux    sync.Mutex
+}
+
+// FileRecord is the record Jexia keeps for each file within a fileset
+type FileRecord struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	URL       string    `json:"url"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Fields holds any custom fields stored alongside the file, such as the metadata passed to Upload
+	Fields map[string]interface{} `json:"-"`
+}
+
+// fileRecordFields are the fields of a FileRecord which are not custom fields
+var fileRecordFields = []string{"id", "name", "size", "url", "status", "created_at", "updated_at"}
+
+// UnmarshalJSON decodes the known file fields and collects the remaining custom fields
+func (r *FileRecord) UnmarshalJSON(b []byte) error {
+	// fileRecord prevents UnmarshalJSON from being called recursively
+	type fileRecord FileRecord
+	var record fileRecord
+	err := json.Unmarshal(b, &record)
+	if err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(b, &fields)
+	if err != nil {
+		return err
+	}
+	for _, name := range fileRecordFields {
+		delete(fields, name)
+	}
+	if len(fields) > 0 {
+		record.Fields = fields
+	}
+	*r = FileRecord(record)
+	return nil
+}
+
+// Path returns the path the file was uploaded with by SyncDir, or its name if it was uploaded another way
+func (r *FileRecord) Path() string {
+	if path, ok := r.Fields[SyncPathField].(string); ok {
+		return path
+	}
+	return r.Name
+}
+
+// DownloadOption allows a download to be configured with different options.
+type DownloadOption func(*downloadConfig)
+
+// downloadConfig holds the values set by each DownloadOption
+type downloadConfig struct {
+	offset int64
+}
+
+// FromOffset starts the download at the given byte offset using a http range request
+// This allows a partial download to be resumed by passing the number of bytes already received
+func FromOffset(offset int64) DownloadOption {
+	return func(d *downloadConfig) {
+		d.offset = offset
+	}
+}
+
+// GetFileset returns a fileset instance that can be used to perform actions against
+func (c *Client) GetFileset(name string) *Fileset {
+	return &Fileset{
+		Name:   name,
+		Client: c,
+	}
+}
+
+// GetName fetches the fileset name
+func (f *Fileset) GetName() string {
+	f.mux.Lock()
+	name := f.Name
+	f.mux.Unlock()
+	return name
+}
+
+// GetClient fetches the current client
+func (f *Fileset) GetClient() *Client {
+	f.mux.Lock()
+	client := f.Client
+	f.mux.Unlock()
+	return client
+}
+
+// Upload streams a file to the fileset as a multipart form, the metadata is stored alongside the file as custom fields
+// The file is read as the request is sent, so it is never held in memory in full
+// Its content type is detected unless set with WithContentType, and its SHA-256 is stored in the ChecksumField
+// The client timeout does not apply, if the context is cancelled the upload is aborted and an error matching ErrUploadCancelled is returned
+func (f *Fileset) Upload(ctx context.Context, filename string, file io.Reader, metadata map[string]interface{}, opts ...UploadOption) (*FileRecord, error) {
+	var record FileRecord
+	config := newUploadConfig(file, opts)
+	if config.contentType == "" {
+		config.contentType, file = detectContentType(filename, file)
+	}
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		// Closing with a nil error is the same as a normal close, any other error is passed on to the http client
+		writer.CloseWithError(writeUploadForm(form, filename, config.contentType, newProgressReader(ctx, file, config), metadata))
+	}()
+
+	req, err := f.GetClient().buildRequest(
+		http.MethodPost,
+		fmt.Sprintf("%v/fs/%v", f.GetClient().projectURL, f.GetName()),
+		setContext(ctx),
+		addToken(f.GetClient().GetToken().Access),
+		setHeader("Content-Type", form.FormDataContentType()),
+		setBodyReader(body),
+	)
+	if err == nil {
+		// Sending a large file may take longer than the client timeout, so only the context can end the upload
+		err = f.GetClient().executeRequestWith(f.GetClient().untimedHTTP(), req, &record)
+	}
+	if err != nil {
+		// Unblock the form writer if the request ended before the whole file was read
+		body.CloseWithError(err)
+		return nil, cancelledError(ctx, err)
+	}
+	return &record, nil
+}
+
+// quoteEscaper escapes the file name within the Content-Disposition header, as done by multipart.CreateFormFile
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeUploadForm writes the file and metadata parts which make up an upload request
+// The metadata is written after the file so that the checksum worked out while sending the file can be included
+func writeUploadForm(form *multipart.Writer, filename, contentType string, file io.Reader, metadata map[string]interface{}) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(filename)))
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	hash := newHashReader(file)
+	_, err = io.Copy(part, hash)
+	if err != nil {
+		return err
+	}
+
+	data, err := marshal(withChecksum(metadata, hash.Checksum()))
+	if err != nil {
+		return err
+	}
+	err = form.WriteField("data", string(data))
+	if err != nil {
+		return err
+	}
+	return form.Close()
+}
+
+// withChecksum copies the metadata with the checksum added, so the map passed in by the caller is left untouched
+func withChecksum(metadata map[string]interface{}, checksum string) map[string]interface{} {
+	fields := make(map[string]interface{}, len(metadata)+1)
+	for key, value := range metadata {
+		fields[key] = value
+	}
+	fields[ChecksumField] = checksum
+	return fields
+}
+
+// Select fetches the records of the files within the fileset
+// Options such as Where, SortAsc and Limit can be passed to filter, sort and page the records
+func (f *Fileset) Select(ctx context.Context, opts ...QueryOption) ([]FileRecord, error) {
+	var records []FileRecord
+	query, err := buildQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	err = f.GetClient().get(
+		fmt.Sprintf("%v/fs/%v%v", f.GetClient().projectURL, f.GetName(), query),
+		&records,
+		setContext(ctx),
+		addToken(f.GetClient().GetToken().Access),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Update sets the custom fields of the records which match the options, returning the updated records
+// The fields can be any value which marshals into a JSON object, such as a map or a struct
+func (f *Fileset) Update(ctx context.Context, fields interface{}, opts ...QueryOption) ([]FileRecord, error) {
+	var records []FileRecord
+	query, err := buildQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	err = f.GetClient().put(
+		fmt.Sprintf("%v/fs/%v%v", f.GetClient().projectURL, f.GetName(), query),
+		&records,
+		setContext(ctx),
+		addToken(f.GetClient().GetToken().Access),
+		setBody(payload),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Delete removes the records which match the options, returning the deleted records
+// Jexia removes the stored file alongside its record, so the file can no longer be downloaded
+// A Where option is required, otherwise ErrConditionRequired is returned rather than removing every file
+func (f *Fileset) Delete(ctx context.Context, opts ...QueryOption) ([]FileRecord, error) {
+	var records []FileRecord
+	err := requireCondition(opts)
+	if err != nil {
+		return nil, err
+	}
+	query, err := buildQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	err = f.GetClient().delete(
+		fmt.Sprintf("%v/fs/%v%v", f.GetClient().projectURL, f.GetName(), query),
+		&records,
+		setContext(ctx),
+		addToken(f.GetClient().GetToken().Access),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// getRecord fetches the record of a single file by its id
+func (f *Fileset) getRecord(ctx context.Context, id string) (*FileRecord, error) {
+	records, err := f.Select(ctx, Where(Field("id").IsEqualTo(id)))
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, &Error{
+			ID:        "e007",
+			Message:   fmt.Errorf("File %v does not exist in fileset %v", id, f.GetName()).Error(),
+			Origin:    Internal,
+			Temporary: false,
+		}
+	}
+	return &records[0], nil
+}
+
+// Open resolves the url of a file from its record and returns the body of the file as it is downloaded
+// When read from the start the file is checked against its stored checksum, see ErrChecksumMismatch
+// The client timeout does not apply while the file is read, use the context to limit how long the download may take
+// The returned reader must be closed once finished with
+func (f *Fileset) Open(ctx context.Context, id string, opts ...DownloadOption) (io.ReadCloser, error) {
+	config := downloadConfig{}
+	for _, o := range opts {
+		o(&config)
+	}
+
+	record, err := f.getRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.URL == "" {
+		// Files which are still being processed, or have failed, do not have a url to download from
+		return nil, &Error{
+			ID:        "e008",
+			Message:   fmt.Errorf("File %v can not be downloaded, current status: %v", id, record.Status).Error(),
+			Origin:    Internal,
+			Temporary: record.Status == FileStatusInProgress,
+		}
+	}
+
+	// The token is not sent as the file url may be hosted outside of the project
+	requestOpts := []requestOption{setContext(ctx)}
+	if config.offset > 0 {
+		requestOpts = append(requestOpts, addHeader("Range", fmt.Sprintf("bytes=%v-", config.offset)))
+	}
+	resp, err := f.GetClient().getStream(record.URL, requestOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A file can only be verified when read from the start, partial downloads are left for the caller to check
+	checksum, ok := record.Fields[ChecksumField].(string)
+	if config.offset == 0 && ok {
+		return newVerifyingReader(resp.Body, checksum), nil
+	}
+
+	// If the range was ignored, the whole file is sent so skip what has already been received
+	if config.offset > 0 && resp.StatusCode != http.StatusPartialContent {
+		_, err = io.CopyN(ioutil.Discard, resp.Body, config.offset)
+		if err != nil {
+			resp.Body.Close()
+			return nil, &Error{
+				ID:        "e006",
+				Message:   fmt.Errorf("Unable to read http body: %w", err).Error(),
+				Origin:    Internal,
+				Temporary: false,
+			}
+		}
+	}
+	return resp.Body, nil
+}
+
+// Download streams the file into the writer, returning the number of bytes written
+// Pass FromOffset with the bytes already written to resume a partial download
+func (f *Fileset) Download(ctx context.Context, id string, w io.Writer, opts ...DownloadOption) (int64, error) {
+	body, err := f.Open(ctx, id, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	written, err := io.Copy(w, body)
+	if errors.Is(err, ErrChecksumMismatch) {
+		return written, err
+	}
+	if err != nil {
+		return written, &Error{
+			ID:        "e006",
+			Message:   fmt.Errorf("Unable to read http body: %w", err).Error(),
+			Origin:    Internal,
+			Temporary: true,
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,385 @@
+package jexiasdkgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNameFromFileset(t *testing.T) {
+	var fileset *Fileset
+	fileset = &Fileset{
+		Name: "name",
+	}
+	assert.Equal(t, "name", fileset.GetName())
+}
+
+func TestGetClientFromFileset(t *testing.T) {
+	var fileset *Fileset
+	var client *Client
+	client = &Client{
+		projectID: "testing",
+	}
+	fileset = &Fileset{
+		Client: client,
+	}
+	assert.Equal(t, client, fileset.GetClient())
+}
+
+func TestClientPassedAsMemoryPointerToFileset(t *testing.T) {
+	client := NewClient(
+		"projectID",
+		"projectZone",
+	)
+	fileset := client.GetFileset("filesetName")
+	assert.Equal(t, "filesetName", fileset.GetName())
+	assert.Equal(t, client, fileset.GetClient())
+
+	client.SetToken(Token{
+		Access: "yourNewAccessToken",
+	})
+	assert.Equal(t, "yourNewAccessToken", fileset.GetClient().GetToken().Access)
+}
+
+func TestFilesetUpload(t *testing.T) {
+	var token Token
+	token = Token{
+		Access: "yourCurrentAccessToken",
+	}
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/fs/test", req.URL.String())
+		assert.Equal(t, http.MethodPost, req.Method)
+		headers := req.Header
+		assert.Equal(t, 1, len(headers["Authorization"]))
+		assert.Equal(t, fmt.Sprintf("Bearer %v", token.Access), headers["Authorization"][0])
+		assert.True(t, strings.HasPrefix(headers.Get("Content-Type"), "multipart/form-data"))
+
+		err := req.ParseMultipartForm(1024)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"checksum":"b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9","owner":"tester"}`, req.FormValue("data"))
+		file, header, err := req.FormFile("file")
+		assert.NoError(t, err)
+		assert.Equal(t, "hello.txt", header.Filename)
+		assert.Equal(t, "text/plain; charset=utf-8", header.Header.Get("Content-Type"))
+		content, _ := ioutil.ReadAll(file)
+		assert.Equal(t, "hello world", string(content))
+
+		// Send response to be tested
+		rw.Write([]byte(`{"id":"test","created_at":"2020-07-08T16:08:50.304789Z","updated_at":"2020-07-08T16:08:50.304789Z","name":"hello.txt","size":11,"url":"https://files.example.com/hello.txt","status":"in_progress"}`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	client.SetToken(token)
+	fileset := client.GetFileset("test")
+
+	record, err := fileset.Upload(context.Background(), "hello.txt", strings.NewReader("hello world"), map[string]interface{}{"owner": "tester"})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", record.ID)
+	assert.Equal(t, "hello.txt", record.Name)
+	assert.Equal(t, int64(11), record.Size)
+	assert.Equal(t, "https://files.example.com/hello.txt", record.URL)
+	assert.Equal(t, FileStatusInProgress, record.Status)
+}
+
+// slowReader returns one byte at a time, waiting before each
+type slowReader struct {
+	content string
+	delay   time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.content) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	n := copy(p[:1], r.content)
+	r.content = r.content[n:]
+	return n, nil
+}
+
+func TestFilesetUploadSlowerThanClientTimeout(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, header, err := req.FormFile("file")
+		assert.NoError(t, err)
+		rw.Write([]byte(fmt.Sprintf(`{"id":"test","name":"%v","size":11,"status":"in_progress"}`, header.Filename)))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+		SetHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}),
+	)
+	fileset := client.GetFileset("test")
+
+	// Sending the whole file takes longer than the client timeout
+	file := &slowReader{content: "hello world", delay: 10 * time.Millisecond}
+	record, err := fileset.Upload(context.Background(), "hello.txt", file, nil, WithContentType("text/plain"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello.txt", record.Name)
+}
+
+func TestFilesetUploadAPIError(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusUnauthorized)
+		rw.Write([]byte(`[{"request_id":"abc","message":"unauthorized"}]`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	record, err := fileset.Upload(context.Background(), "hello.txt", strings.NewReader("hello world"), nil)
+	assert.Nil(t, record)
+	assert.Equal(t, &Error{ID: "abc", Message: "unauthorized", Origin: API, Status: http.StatusUnauthorized}, err)
+}
+
+func newDownloadServer(t *testing.T, content string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/fs/test":
+			assert.Equal(t, http.MethodGet, req.Method)
+			assert.Equal(t, `[{"field":"id"},"=","test"]`, req.URL.Query().Get("cond"))
+			assert.Equal(t, "Bearer yourCurrentAccessToken", req.Header.Get("Authorization"))
+			rw.Write([]byte(fmt.Sprintf(`[{"id":"test","name":"hello.txt","size":%v,"url":"%v/files/hello.txt","status":"completed"}]`, len(content), server.URL)))
+		case "/files/hello.txt":
+			// The file url should never receive the project token
+			assert.Equal(t, "", req.Header.Get("Authorization"))
+			http.ServeContent(rw, req, "hello.txt", time.Time{}, strings.NewReader(content))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestFilesetDownload(t *testing.T) {
+	server := newDownloadServer(t, "hello world")
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	client.SetToken(Token{Access: "yourCurrentAccessToken"})
+	fileset := client.GetFileset("test")
+
+	var buffer bytes.Buffer
+	written, err := fileset.Download(context.Background(), "test", &buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), written)
+	assert.Equal(t, "hello world", buffer.String())
+}
+
+func TestFilesetDownloadFromOffset(t *testing.T) {
+	server := newDownloadServer(t, "hello world")
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	client.SetToken(Token{Access: "yourCurrentAccessToken"})
+	fileset := client.GetFileset("test")
+
+	body, err := fileset.Open(context.Background(), "test", FromOffset(6))
+	assert.NoError(t, err)
+	defer body.Close()
+	content, err := ioutil.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "world", string(content))
+}
+
+func TestFilesetDownloadSlowerThanClientTimeout(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/fs/test":
+			rw.Write([]byte(fmt.Sprintf(`[{"id":"test","name":"hello.txt","size":11,"url":"http://%v/files/hello.txt","status":"completed"}]`, req.Host)))
+		case "/files/hello.txt":
+			// The file arrives a word at a time, taking longer than the client timeout in total
+			for _, word := range []string{"hello", " ", "world"} {
+				rw.Write([]byte(word))
+				rw.(http.Flusher).Flush()
+				time.Sleep(40 * time.Millisecond)
+			}
+		}
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+		SetHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}),
+	)
+	fileset := client.GetFileset("test")
+
+	var buffer bytes.Buffer
+	written, err := fileset.Download(context.Background(), "test", &buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), written)
+	assert.Equal(t, "hello world", buffer.String())
+}
+
+func TestFilesetOpenMissingFile(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(`[]`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	body, err := fileset.Open(context.Background(), "missing")
+	assert.Nil(t, body)
+	assert.Equal(t, "e007", err.(*Error).ID)
+}
+
+func TestFilesetSelect(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/fs/test", req.URL.Path)
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, `[{"field":"owner"},"=","tester"]`, req.URL.Query().Get("cond"))
+		assert.Equal(t, `{"limit":1}`, req.URL.Query().Get("range"))
+		assert.Equal(t, "Bearer yourCurrentAccessToken", req.Header.Get("Authorization"))
+		// Send response to be tested
+		rw.Write([]byte(`[{"id":"test","created_at":"2020-07-08T16:08:50.304789Z","updated_at":"2020-07-08T16:08:50.304789Z","name":"hello.txt","size":11,"url":"https://files.example.com/hello.txt","status":"completed","owner":"tester"}]`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	client.SetToken(Token{Access: "yourCurrentAccessToken"})
+	fileset := client.GetFileset("test")
+
+	records, err := fileset.Select(context.Background(), Where(Field("owner").IsEqualTo("tester")), Limit(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []FileRecord{{
+		ID:        "test",
+		Name:      "hello.txt",
+		Size:      11,
+		URL:       "https://files.example.com/hello.txt",
+		Status:    FileStatusCompleted,
+		CreatedAt: time.Date(2020, 07, 8, 16, 8, 50, 304789000, time.UTC),
+		UpdatedAt: time.Date(2020, 07, 8, 16, 8, 50, 304789000, time.UTC),
+		Fields:    map[string]interface{}{"owner": "tester"},
+	}}, records)
+}
+
+func TestFilesetUpdate(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/fs/test", req.URL.Path)
+		assert.Equal(t, http.MethodPut, req.Method)
+		assert.Equal(t, `[{"field":"id"},"=","test"]`, req.URL.Query().Get("cond"))
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.Equal(t, `{"owner":"someone"}`, string(body))
+		// Send response to be tested
+		rw.Write([]byte(`[{"id":"test","name":"hello.txt","owner":"someone"}]`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	records, err := fileset.Update(context.Background(), map[string]string{"owner": "someone"}, Where(Field("id").IsEqualTo("test")))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "someone", records[0].Fields["owner"])
+}
+
+func TestFilesetDelete(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/fs/test", req.URL.Path)
+		assert.Equal(t, http.MethodDelete, req.Method)
+		assert.Equal(t, `[{"field":"id"},"=","test"]`, req.URL.Query().Get("cond"))
+		// Send response to be tested
+		rw.Write([]byte(`[{"id":"test","name":"hello.txt"}]`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	records, err := fileset.Delete(context.Background(), Where(Field("id").IsEqualTo("test")))
+	assert.NoError(t, err)
+	assert.Equal(t, []FileRecord{{ID: "test", Name: "hello.txt"}}, records)
+}
+
+func TestFilesetDeleteRequiresCondition(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Error("No request should be sent without a condition")
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+
+	records, err := client.GetFileset("test").Delete(context.Background(), Limit(1))
+	assert.True(t, errors.Is(err, ErrConditionRequired))
+	assert.Nil(t, records)
+}
@@ -0,0 +1,255 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// ActionUploadCompleted is the action of a fileset event sent when files finish processing and can be downloaded
+	ActionUploadCompleted Action = "upload completed"
+	// ActionUploadFailed is the action of a fileset event sent when files could not be stored
+	ActionUploadFailed Action = "upload failed"
+)
+
+// allFileActions are watched when no actions are passed to Fileset.Watch
+var allFileActions = []string{
+	string(ActionCreated),
+	string(ActionUpdated),
+	string(ActionDeleted),
+	string(ActionUploadCompleted),
+	string(ActionUploadFailed),
+}
+
+// FileEvent is a change made to the files of a fileset, received from the realtime service
+type FileEvent struct {
+	Action  Action
+	Fileset string
+	// Records are the files the action was applied to, deleted files only have their ID set
+	Records    []FileRecord
+	ModifierID string
+	Timestamp  time.Time
+}
+
+// FilesetWatch receives the changes made to a fileset
+type FilesetWatch struct {
+	*Subscription
+	// Events receives each change in the order it was made, it is closed once the watch ends
+	Events <-chan FileEvent
+}
+
+// fileWatcher turns the events of the realtime service into file events, keeping track of the files still being processed
+type fileWatcher struct {
+	fileset   *Fileset
+	config    watchConfig
+	requested map[Action]bool
+	// inProgress holds the ids of files last seen being processed, so their completion can be reported
+	inProgress map[string]bool
+}
+
+// Watch subscribes to the changes made to the fileset, pass actions such as ActionUploadCompleted to limit the events, otherwise every action is watched
+// ActionUploadCompleted and ActionUploadFailed are reported when a file the watch has seen being processed finishes
+// Files already being processed when the watch starts are looked up, so their completion is reported too
+// Passing Where limits the events to files matching the condition, the same as it would for Select
+// Watches share the realtime connection of the client, which is closed once the last of them ends, see SetRealtimeOptions to configure it
+func (f *Fileset) Watch(ctx context.Context, opts ...WatchOption) (*FilesetWatch, error) {
+	config := newWatchConfig(opts, allFileActions)
+	w := &fileWatcher{
+		fileset:    f,
+		config:     config,
+		requested:  make(map[Action]bool),
+		inProgress: make(map[string]bool),
+	}
+	// The completion of an upload is seen in the created and updated events of the realtime service
+	var actions []string
+	for _, action := range config.actions {
+		w.requested[Action(action)] = true
+		if action != string(ActionUploadCompleted) && action != string(ActionUploadFailed) {
+			actions = append(actions, action)
+		}
+	}
+	if w.requested[ActionUploadCompleted] || w.requested[ActionUploadFailed] {
+		actions = appendMissing(actions, string(ActionCreated), string(ActionUpdated))
+		processing, err := f.listAll(ctx, Where(Field("status").IsEqualTo(FileStatusInProgress)), Outputs("id"))
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range processing {
+			w.inProgress[record.ID] = true
+		}
+	}
+
+	realtime, err := f.GetClient().acquireRealtime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := realtime.subscribe(
+		ctx,
+		fmt.Sprintf("rest api:%v", f.GetName()),
+		rtcResource{Type: "fs", Name: f.GetName()},
+		actions,
+		config.condition,
+		newSubscribeConfig(config.subscribe),
+	)
+	if err != nil {
+		f.GetClient().releaseRealtime(realtime)
+		return nil, err
+	}
+
+	events := make(chan FileEvent)
+	go func() {
+		defer close(events)
+		// Released once unsubscribed, so the connection is not closed while the unsubscribe is sent
+		defer f.GetClient().releaseRealtime(realtime)
+		realtime.relay(ctx, s, func(received rtcEventData) bool {
+			for _, event := range w.events(ctx, received) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		})
+	}()
+	return &FilesetWatch{
+		Subscription: &Subscription{s: s},
+		Events:       events,
+	}, nil
+}
+
+// appendMissing adds the values which are not already in the list
+func appendMissing(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			found = found || existing == value
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// events converts an event from the realtime service into the file events which were asked for
+func (w *fileWatcher) events(ctx context.Context, received rtcEventData) []FileEvent {
+	action := Action(received.Action)
+	records := w.records(ctx, action, received.Data)
+
+	var completed, failed []FileRecord
+	for _, record := range records {
+		switch {
+		case action == ActionDeleted:
+			delete(w.inProgress, record.ID)
+		case record.Status == FileStatusInProgress:
+			w.inProgress[record.ID] = true
+		case record.Status == FileStatusCompleted || record.Status == FileStatusFailed:
+			// A file created already completed was processed straight away, so is reported as well
+			if w.inProgress[record.ID] || action == ActionCreated {
+				if record.Status == FileStatusCompleted {
+					completed = append(completed, record)
+				} else {
+					failed = append(failed, record)
+				}
+			}
+			delete(w.inProgress, record.ID)
+		}
+	}
+
+	var events []FileEvent
+	add := func(action Action, records []FileRecord) {
+		records = w.matching(action, records)
+		if !w.requested[action] || len(records) == 0 {
+			return
+		}
+		events = append(events, FileEvent{
+			Action:     action,
+			Fileset:    received.Resource.Name,
+			Records:    records,
+			ModifierID: received.Modifier.ID,
+			Timestamp:  received.Timestamp,
+		})
+	}
+	add(action, records)
+	add(ActionUploadCompleted, completed)
+	add(ActionUploadFailed, failed)
+	return events
+}
+
+// records decodes the files of the event, fetching the full records of created and updated files if the event only has their ids
+func (w *fileWatcher) records(ctx context.Context, action Action, data json.RawMessage) []FileRecord {
+	var fields []map[string]interface{}
+	var records []FileRecord
+	json.Unmarshal(data, &fields)
+	json.Unmarshal(data, &records)
+	if action == ActionDeleted {
+		return records
+	}
+
+	var ids []interface{}
+	for i, record := range fields {
+		if _, ok := record["status"]; !ok && i < len(records) {
+			ids = append(ids, records[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return records
+	}
+	fetched, err := w.fileset.Select(ctx, Where(Field("id").IsInArray(ids...)))
+	if err != nil {
+		// The ids are still worth passing on even if the rest of the record could not be fetched
+		return records
+	}
+	byID := make(map[string]FileRecord, len(fetched))
+	for _, record := range fetched {
+		byID[record.ID] = record
+	}
+	for i, record := range records {
+		if full, ok := byID[record.ID]; ok {
+			records[i] = full
+		}
+	}
+	return records
+}
+
+// matching keeps the files which match the condition of the watch
+// Deleted files can not be checked, so are kept rather than silently dropped
+func (w *fileWatcher) matching(action Action, records []FileRecord) []FileRecord {
+	if w.config.condition == nil || action == ActionDeleted {
+		return records
+	}
+	var matched []FileRecord
+	for _, record := range records {
+		isMatch, ok := w.config.condition.evaluate(record.values())
+		if isMatch || !ok {
+			matched = append(matched, record)
+		}
+	}
+	return matched
+}
+
+// values returns the fields of the record as they would be decoded from JSON, including its custom fields
+func (r FileRecord) values() map[string]interface{} {
+	fields := make(map[string]interface{}, len(r.Fields)+len(fileRecordFields))
+	for key, value := range r.Fields {
+		fields[key] = value
+	}
+	known := map[string]interface{}{
+		"id":         r.ID,
+		"name":       r.Name,
+		"size":       r.Size,
+		"url":        r.URL,
+		"status":     r.Status,
+		"created_at": r.CreatedAt,
+		"updated_at": r.UpdatedAt,
+	}
+	b, _ := marshal(known)
+	json.Unmarshal(b, &known)
+	for key, value := range known {
+		fields[key] = value
+	}
+	return fields
+}
@@ -0,0 +1,104 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFileWatchServer acts as a fileset with f1 being processed, sending the events to the first subscriber
+func newFileWatchServer(t *testing.T, actions []string, events ...string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/fs/uploads" {
+			switch req.URL.Query().Get("cond") {
+			case `[{"field":"status"},"=","in_progress"]`:
+				rw.Write([]byte(`[{"id":"f1"}]`))
+			case `[{"field":"id"},"in",["f2"]]`:
+				rw.Write([]byte(`[{"id":"f2","name":"b.txt","status":"in_progress"}]`))
+			default:
+				t.Errorf("Unexpected condition %v", req.URL.Query().Get("cond"))
+			}
+			return
+		}
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.Equal(t, rtcSubscription{Action: actions, Namespace: "rest api:uploads"}, arguments)
+		for _, event := range events {
+			writeEvent(t, conn, event)
+		}
+		conn.ReadMessage()
+	}))
+}
+
+// readFileEvents reads the action and file ids of each event until none arrive for a while
+func readFileEvents(watch *FilesetWatch) [][]string {
+	var received [][]string
+	for {
+		select {
+		case event := <-watch.Events:
+			summary := []string{string(event.Action)}
+			for _, record := range event.Records {
+				summary = append(summary, record.ID)
+			}
+			received = append(received, summary)
+		case <-time.After(200 * time.Millisecond):
+			return received
+		}
+	}
+}
+
+var fileEvents = []string{
+	`{"action":"updated","resource":{"type":"fs","name":"uploads"},"data":[{"id":"f1","name":"a.txt","status":"completed"}]}`,
+	`{"action":"created","resource":{"type":"fs","name":"uploads"},"data":[{"id":"f2"}]}`,
+	`{"action":"updated","resource":{"type":"fs","name":"uploads"},"data":[{"id":"f2","name":"b.txt","status":"failed"}]}`,
+	`{"action":"updated","resource":{"type":"fs","name":"uploads"},"data":[{"id":"f3","name":"c.txt","status":"completed"}]}`,
+	`{"action":"deleted","resource":{"type":"fs","name":"uploads"},"data":[{"id":"f1"}]}`,
+}
+
+func TestFilesetWatch(t *testing.T) {
+	server := newFileWatchServer(t, []string{"created", "updated", "deleted"}, fileEvents...)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch, err := client.GetFileset("uploads").Watch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"updated", "f1"},
+		{"upload completed", "f1"},
+		// The created event only had the id, so the rest of the record was fetched
+		{"created", "f2"},
+		{"updated", "f2"},
+		{"upload failed", "f2"},
+		// f3 was not seen being processed, so this is not its completion
+		{"updated", "f3"},
+		{"deleted", "f1"},
+	}, readFileEvents(watch))
+}
+
+func TestFilesetWatchUploadCompleted(t *testing.T) {
+	server := newFileWatchServer(t, []string{"created", "updated"}, fileEvents...)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch, err := client.GetFileset("uploads").Watch(ctx, ActionUploadCompleted)
+	assert.NoError(t, err)
+	received := readFileEvents(watch)
+	assert.Equal(t, [][]string{{"upload completed", "f1"}}, received)
+}
@@ -0,0 +1,267 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FilesetFS exposes the files within a fileset through the io/fs interfaces, such as for http.FS or template.ParseFS
+// File paths are those set by SyncDir, or the file names otherwise, directories are made up from the '/' in each path
+type FilesetFS struct {
+	ctx     context.Context
+	fileset *Fileset
+}
+
+// FS returns an fs.FS over the fileset, the context is used for every request made through it
+func (f *Fileset) FS(ctx context.Context) *FilesetFS {
+	return &FilesetFS{
+		ctx:     ctx,
+		fileset: f,
+	}
+}
+
+// Open opens the named file or directory, the contents of a file are only downloaded once it is read
+func (fsys *FilesetFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	record, err := fsys.find(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if record != nil {
+		return &filesetFile{fsys: fsys, record: record}, nil
+	}
+	entries, err := fsys.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &filesetDir{info: dirInfo(name), entries: entries}, nil
+}
+
+// Stat returns the details of the named file or directory without opening it
+func (fsys *FilesetFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	record, err := fsys.find(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	if record != nil {
+		return fileInfo{record: record}, nil
+	}
+	_, err = fsys.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return dirInfo(name), nil
+}
+
+// ReadDir lists the named directory, sorted by name
+func (fsys *FilesetFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries, err := fsys.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+// find fetches the record of the file at the path, returning nil if there is no such file
+func (fsys *FilesetFS) find(name string) (*FileRecord, error) {
+	if name == "." {
+		return nil, nil
+	}
+	records, err := fsys.fileset.Select(
+		fsys.ctx,
+		Where(Field(SyncPathField).IsEqualTo(name).Or(Field("name").IsEqualTo(name))),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// A file with a matching name may still have been uploaded with a different path
+	for i := range records {
+		if records[i].Path() == name {
+			return &records[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// readDir lists the direct children of a directory, returning fs.ErrNotExist if no file is within it
+func (fsys *FilesetFS) readDir(name string) ([]fs.DirEntry, error) {
+	records, err := fsys.fileset.listAll(fsys.ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	children := map[string]fs.DirEntry{}
+	for i := range records {
+		p := records[i].Path()
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		child := strings.TrimPrefix(p, prefix)
+		if i := strings.Index(child, "/"); i >= 0 {
+			child = child[:i]
+			children[child] = fs.FileInfoToDirEntry(dirInfo(prefix + child))
+			continue
+		}
+		if _, ok := children[child]; !ok {
+			children[child] = fs.FileInfoToDirEntry(fileInfo{record: &records[i]})
+		}
+	}
+	if len(children) == 0 && name != "." {
+		return nil, fs.ErrNotExist
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, entry := range children {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// fileInfo describes a file using its record, the record is returned by Sys
+type fileInfo struct {
+	record *FileRecord
+}
+
+func (i fileInfo) Name() string       { return path.Base(i.record.Path()) }
+func (i fileInfo) Size() int64        { return i.record.Size }
+func (i fileInfo) Mode() fs.FileMode  { return 0444 }
+func (i fileInfo) ModTime() time.Time { return i.record.UpdatedAt }
+func (i fileInfo) IsDir() bool        { return false }
+func (i fileInfo) Sys() interface{}   { return i.record }
+
+// directoryInfo describes a directory, which only exists as part of the paths of the files within it
+type directoryInfo struct {
+	name string
+}
+
+func dirInfo(name string) directoryInfo {
+	return directoryInfo{name: path.Base(name)}
+}
+
+func (i directoryInfo) Name() string       { return i.name }
+func (i directoryInfo) Size() int64        { return 0 }
+func (i directoryInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (i directoryInfo) ModTime() time.Time { return time.Time{} }
+func (i directoryInfo) IsDir() bool        { return true }
+func (i directoryInfo) Sys() interface{}   { return nil }
+
+// filesetFile streams the contents of a file through Fileset.Open, seeking starts a new ranged download
+type filesetFile struct {
+	fsys     *FilesetFS
+	record   *FileRecord
+	body     io.ReadCloser
+	position int64
+	closed   bool
+}
+
+func (f *filesetFile) Stat() (fs.FileInfo, error) {
+	return fileInfo{record: f.record}, nil
+}
+
+func (f *filesetFile) Read(b []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.record.Path(), Err: fs.ErrClosed}
+	}
+	if f.position >= f.record.Size {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		body, err := f.fsys.fileset.Open(f.fsys.ctx, f.record.ID, FromOffset(f.position))
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.record.Path(), Err: err}
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(b)
+	f.position += int64(n)
+	return n, err
+}
+
+func (f *filesetFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.record.Path(), Err: fs.ErrClosed}
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.position
+	case io.SeekEnd:
+		offset += f.record.Size
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.record.Path(), Err: fs.ErrInvalid}
+	}
+	if offset != f.position && f.body != nil {
+		// The next read starts a new download from the new position
+		f.body.Close()
+		f.body = nil
+	}
+	f.position = offset
+	return offset, nil
+}
+
+func (f *filesetFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.record.Path(), Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.body != nil {
+		return f.body.Close()
+	}
+	return nil
+}
+
+// filesetDir is an open directory, its entries are fetched when it is opened
+type filesetDir struct {
+	info    directoryInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *filesetDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *filesetDir) Read(b []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *filesetDir) Close() error {
+	return nil
+}
+
+func (d *filesetDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	d.offset += count
+	return remaining[:count], nil
+}
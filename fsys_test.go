@@ -0,0 +1,134 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFSServer serves file records for the given paths, with the content of each file being its own path
+func newFSServer(t *testing.T, paths ...string) *httptest.Server {
+	var server *httptest.Server
+	records := make([]map[string]interface{}, len(paths))
+	for i, p := range paths {
+		records[i] = map[string]interface{}{
+			"id":          fmt.Sprint(i),
+			"name":        p[strings.LastIndex(p, "/")+1:],
+			"size":        len(p),
+			"status":      FileStatusCompleted,
+			"updated_at":  "2020-07-08T16:08:50.304789Z",
+			SyncPathField: p,
+		}
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/files/") {
+			p := strings.TrimPrefix(req.URL.Path, "/files/")
+			http.ServeContent(rw, req, p, time.Time{}, strings.NewReader(p))
+			return
+		}
+		assert.Equal(t, "/fs/test", req.URL.Path)
+		var rng struct {
+			Limit  int `json:"limit"`
+			Offset int `json:"offset"`
+		}
+		json.Unmarshal([]byte(req.URL.Query().Get("range")), &rng)
+		var cond []interface{}
+		json.Unmarshal([]byte(req.URL.Query().Get("cond")), &cond)
+
+		page := []map[string]interface{}{}
+		for i, record := range records {
+			// The only conditions used are fields being equal to a value, joined by "or"
+			matched := len(cond) == 0
+			for c := 0; c+2 < len(cond); c += 4 {
+				field := cond[c].(map[string]interface{})["field"].(string)
+				matched = matched || record[field] == cond[c+2]
+			}
+			if !matched {
+				continue
+			}
+			if rng.Limit > 0 && (i < rng.Offset || i >= rng.Offset+rng.Limit) {
+				continue
+			}
+			with := map[string]interface{}{"url": fmt.Sprintf("%v/files/%v", server.URL, record[SyncPathField])}
+			for k, v := range record {
+				with[k] = v
+			}
+			page = append(page, with)
+		}
+		payload, _ := json.Marshal(page)
+		rw.Write(payload)
+	}))
+	return server
+}
+
+func TestFilesetFS(t *testing.T) {
+	server := newFSServer(t, "index.html", "css/site.css", "css/print.css", "img/logo/logo.svg")
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fsys := client.GetFileset("test").FS(context.Background())
+
+	err := fstest.TestFS(fsys, "index.html", "css/site.css", "css/print.css", "img/logo/logo.svg")
+	assert.NoError(t, err)
+}
+
+func TestFilesetFSReadFile(t *testing.T) {
+	server := newFSServer(t, "index.html", "css/site.css")
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fsys := client.GetFileset("test").FS(context.Background())
+
+	content, err := fs.ReadFile(fsys, "css/site.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "css/site.css", string(content))
+
+	info, err := fs.Stat(fsys, "css")
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	_, err = fsys.Open("missing.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestFilesetFSFileServer(t *testing.T) {
+	server := newFSServer(t, "index.html")
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileServer := httptest.NewServer(http.FileServer(http.FS(client.GetFileset("test").FS(context.Background()))))
+	defer fileServer.Close()
+
+	resp, err := http.Get(fileServer.URL + "/index.html")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "index.html", string(body))
+}
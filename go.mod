@@ -1,5 +1,8 @@
 module github.com/baileyjm02/jexia-sdk-go
 
-go 1.14
+go 1.16
 
-require github.com/stretchr/testify v1.6.1
+require (
+	github.com/gorilla/websocket v1.4.2
+	github.com/stretchr/testify v1.6.1
+)
@@ -0,0 +1,306 @@
+package jexiasdkgo
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ImportFormat is the format of the rows read by ImportUsers
+type ImportFormat int
+
+const (
+	// ImportNDJSON reads one JSON object per line, each with at least an "email" and "password"
+	ImportNDJSON ImportFormat = iota
+	// ImportCSV reads a header row followed by one user per row, the header must include "email" and "password"
+	ImportCSV
+)
+
+const (
+	// ImportCreated is the status of a row which created a new user
+	ImportCreated = "created"
+	// ImportUpdated is the status of a row which updated the user that already had its email
+	ImportUpdated = "updated"
+	// ImportSkipped is the status of a row which was skipped as a user already had its email
+	ImportSkipped = "skipped"
+	// ImportFailed is the status of a row which could not be read or imported
+	ImportFailed = "failed"
+)
+
+// DefaultImportConcurrency is the number of users imported at the same time by ImportUsers
+const DefaultImportConcurrency = 8
+
+// ImportOption allows a user import to be configured with different options.
+type ImportOption func(*importConfig)
+
+// importConfig holds the values set by each ImportOption
+type importConfig struct {
+	concurrency    int
+	updateExisting bool
+}
+
+// WithImportConcurrency sets the number of users imported at the same time
+func WithImportConcurrency(concurrency int) ImportOption {
+	return func(i *importConfig) {
+		i.concurrency = concurrency
+	}
+}
+
+// UpdateExisting updates the fields of users whose email already exists, rather than skipping them
+// The password of an existing user is never changed
+func UpdateExisting() ImportOption {
+	return func(i *importConfig) {
+		i.updateExisting = true
+	}
+}
+
+// ImportResult is the outcome of a single row, one is written as a line of JSON to the results writer for each row
+type ImportResult struct {
+	// Row is the number of the row within the input, starting at 1 and not counting the CSV header
+	Row    int    `json:"row"`
+	Email  string `json:"email,omitempty"`
+	Status string `json:"status"`
+	UserID string `json:"user_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportSummary counts the rows of each status once an import has finished
+type ImportSummary struct {
+	Created int
+	Updated int
+	Skipped int
+	Failed  int
+}
+
+// importRow is a single user read from the input, or the reason it could not be read
+type importRow struct {
+	number int
+	fields map[string]interface{}
+	err    error
+}
+
+// ImportUsers creates a project user for each row of the input, this needs the client to be authenticated with UseAPKToken
+// Users whose email already exists are skipped unless UpdateExisting is passed
+// The result of every row is written to results as a line of JSON, rows finish out of order so each includes its row number
+func (c *Client) ImportUsers(ctx context.Context, input io.Reader, format ImportFormat, results io.Writer, opts ...ImportOption) (*ImportSummary, error) {
+	config := importConfig{
+		concurrency: DefaultImportConcurrency,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	if config.concurrency < 1 {
+		config.concurrency = 1
+	}
+
+	rows := make(chan importRow)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(rows)
+		readErr <- readImportRows(ctx, input, format, rows)
+	}()
+
+	summary := &ImportSummary{}
+	encoder := json.NewEncoder(results)
+	var writeErr error
+	var mux sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < config.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range rows {
+				result := c.importRow(ctx, row, config)
+				mux.Lock()
+				summary.add(result.Status)
+				if err := encoder.Encode(result); err != nil && writeErr == nil {
+					writeErr = err
+				}
+				mux.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return summary, ctx.Err()
+	}
+	if err := <-readErr; err != nil {
+		return summary, err
+	}
+	return summary, writeErr
+}
+
+// add counts a row with the status
+func (s *ImportSummary) add(status string) {
+	switch status {
+	case ImportCreated:
+		s.Created++
+	case ImportUpdated:
+		s.Updated++
+	case ImportSkipped:
+		s.Skipped++
+	default:
+		s.Failed++
+	}
+}
+
+// importRow creates, updates or skips the user of a single row
+func (c *Client) importRow(ctx context.Context, row importRow, config importConfig) ImportResult {
+	result := ImportResult{Row: row.number}
+	if row.err != nil {
+		return result.failed(row.err)
+	}
+	email, _ := row.fields["email"].(string)
+	password, _ := row.fields["password"].(string)
+	result.Email = email
+	if email == "" {
+		return result.failed(fmt.Errorf("Row has no email"))
+	}
+
+	existing, err := c.ListUsers(ctx, Where(Field("email").IsEqualTo(email)), Limit(1))
+	if err != nil {
+		return result.failed(err)
+	}
+
+	extraFields := make(map[string]interface{}, len(row.fields))
+	for key, value := range row.fields {
+		if key != "email" && key != "password" {
+			extraFields[key] = value
+		}
+	}
+
+	if len(existing) > 0 {
+		result.UserID = existing[0].ID
+		if !config.updateExisting {
+			result.Status = ImportSkipped
+			return result
+		}
+		_, err = c.UpdateUsers(ctx, extraFields, Where(Field("id").IsEqualTo(existing[0].ID)))
+		if err != nil {
+			return result.failed(err)
+		}
+		result.Status = ImportUpdated
+		return result
+	}
+
+	if password == "" {
+		return result.failed(fmt.Errorf("Row has no password"))
+	}
+	user, err := c.SignUpUser(ctx, email, password, extraFields)
+	if err != nil {
+		return result.failed(err)
+	}
+	result.Status = ImportCreated
+	result.UserID = user.ID
+	return result
+}
+
+// failed marks the result as failed with the reason
+func (r ImportResult) failed(err error) ImportResult {
+	r.Status = ImportFailed
+	r.Error = err.Error()
+	return r
+}
+
+// readImportRows reads each row of the input, passing it on until the input ends or the context is cancelled
+// Rows which can not be decoded are passed on with their error, only errors reading the input itself are returned
+func readImportRows(ctx context.Context, input io.Reader, format ImportFormat, rows chan<- importRow) error {
+	send := func(row importRow) bool {
+		select {
+		case rows <- row:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	switch format {
+	case ImportNDJSON:
+		scanner := bufio.NewScanner(input)
+		// Allow for users with large custom fields
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		number := 0
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			number++
+			row := importRow{number: number}
+			row.err = json.Unmarshal([]byte(line), &row.fields)
+			if !send(row) {
+				return nil
+			}
+		}
+		return scanner.Err()
+
+	case ImportCSV:
+		reader := csv.NewReader(input)
+		header, err := reader.Read()
+		if err != nil {
+			return err
+		}
+		// Checked once here, otherwise every row of a file with the wrong header would fail on its own
+		if missing := missingColumns(header, "email", "password"); len(missing) > 0 {
+			return &Error{
+				ID:        "e021",
+				Message:   fmt.Errorf("CSV header is missing the columns: %v", strings.Join(missing, ", ")).Error(),
+				Origin:    Internal,
+				Temporary: false,
+			}
+		}
+		for number := 1; ; number++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return nil
+			}
+			// A malformed row is reported and skipped, any other error comes from the input and would repeat on every read
+			var parseErr *csv.ParseError
+			if err != nil && !errors.As(err, &parseErr) {
+				return err
+			}
+			row := importRow{number: number, err: err}
+			if err == nil {
+				row.fields = make(map[string]interface{}, len(header))
+				for i, column := range header {
+					if i < len(record) && record[i] != "" {
+						row.fields[column] = record[i]
+					}
+				}
+			}
+			if !send(row) {
+				return nil
+			}
+		}
+
+	default:
+		return &Error{
+			ID:        "e014",
+			Message:   fmt.Errorf("Unknown import format: %v", format).Error(),
+			Origin:    Internal,
+			Temporary: false,
+		}
+	}
+}
+
+// missingColumns returns the columns which are not in the header
+func missingColumns(header []string, columns ...string) []string {
+	var missing []string
+	for _, column := range columns {
+		found := false
+		for _, name := range header {
+			found = found || name == column
+		}
+		if !found {
+			missing = append(missing, column)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,155 @@
+package jexiasdkgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newImportServer acts as UMS with one existing user, recording the users signed up and updated
+func newImportServer(t *testing.T, signedUp, updated *[]string) *httptest.Server {
+	var mux sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		mux.Lock()
+		defer mux.Unlock()
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/ums/users":
+			if strings.Contains(req.URL.Query().Get("cond"), `"old@example.com"`) {
+				rw.Write([]byte(`[{"id":"1","email":"old@example.com"}]`))
+				return
+			}
+			rw.Write([]byte(`[]`))
+		case req.Method == http.MethodPut && req.URL.Path == "/ums/users":
+			assert.Equal(t, `[{"field":"id"},"=","1"]`, req.URL.Query().Get("cond"))
+			*updated = append(*updated, string(body))
+			rw.Write([]byte(`[{"id":"1","email":"old@example.com"}]`))
+		case req.Method == http.MethodPost && req.URL.Path == "/ums/signup":
+			var fields map[string]interface{}
+			json.Unmarshal(body, &fields)
+			*signedUp = append(*signedUp, fields["email"].(string))
+			rw.Write([]byte(`{"id":"new-` + fields["email"].(string) + `"}`))
+		default:
+			t.Errorf("Unexpected request %v %v", req.Method, req.URL.Path)
+		}
+	}))
+}
+
+// readImportResults decodes the results written by ImportUsers, sorted by row
+func readImportResults(t *testing.T, results *bytes.Buffer) []ImportResult {
+	var decoded []ImportResult
+	decoder := json.NewDecoder(results)
+	for decoder.More() {
+		var result ImportResult
+		assert.NoError(t, decoder.Decode(&result))
+		decoded = append(decoded, result)
+	}
+	sort.Slice(decoded, func(i, j int) bool { return decoded[i].Row < decoded[j].Row })
+	return decoded
+}
+
+func TestImportUsersNDJSON(t *testing.T) {
+	var signedUp, updated []string
+	server := newImportServer(t, &signedUp, &updated)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	input := strings.NewReader(`{"email":"a@example.com","password":"secret","plan":"pro"}
+{"email":"old@example.com","password":"secret","plan":"pro"}
+
+not json
+{"password":"secret"}
+`)
+	var results bytes.Buffer
+	summary, err := client.ImportUsers(context.Background(), input, ImportNDJSON, &results, WithImportConcurrency(2))
+	assert.NoError(t, err)
+	assert.Equal(t, &ImportSummary{Created: 1, Skipped: 1, Failed: 2}, summary)
+	assert.Equal(t, []string{"a@example.com"}, signedUp)
+	assert.Empty(t, updated)
+
+	decoded := readImportResults(t, &results)
+	assert.Len(t, decoded, 4)
+	assert.Equal(t, ImportResult{Row: 1, Email: "a@example.com", Status: ImportCreated, UserID: "new-a@example.com"}, decoded[0])
+	assert.Equal(t, ImportResult{Row: 2, Email: "old@example.com", Status: ImportSkipped, UserID: "1"}, decoded[1])
+	assert.Equal(t, ImportFailed, decoded[2].Status)
+	assert.NotEmpty(t, decoded[2].Error)
+	assert.Equal(t, ImportResult{Row: 4, Status: ImportFailed, Error: "Row has no email"}, decoded[3])
+}
+
+func TestImportUsersCSVUpdateExisting(t *testing.T) {
+	var signedUp, updated []string
+	server := newImportServer(t, &signedUp, &updated)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	input := strings.NewReader("email,password,plan\nb@example.com,secret,free\nold@example.com,,pro\nc@example.com,,free\n")
+	var results bytes.Buffer
+	summary, err := client.ImportUsers(context.Background(), input, ImportCSV, &results, UpdateExisting())
+	assert.NoError(t, err)
+	assert.Equal(t, &ImportSummary{Created: 1, Updated: 1, Failed: 1}, summary)
+	assert.Equal(t, []string{"b@example.com"}, signedUp)
+	assert.Equal(t, []string{`{"plan":"pro"}`}, updated)
+
+	decoded := readImportResults(t, &results)
+	assert.Len(t, decoded, 3)
+	assert.Equal(t, ImportUpdated, decoded[1].Status)
+	assert.Equal(t, ImportResult{Row: 3, Email: "c@example.com", Status: ImportFailed, Error: "Row has no password"}, decoded[2])
+}
+
+func TestImportUsersCSVReadError(t *testing.T) {
+	var signedUp, updated []string
+	server := newImportServer(t, &signedUp, &updated)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	// A failing input returns the same error on every read, so the import stops rather than failing rows forever
+	failure := errors.New("connection lost")
+	input := io.MultiReader(strings.NewReader("email,password\nb@example.com,secret\n"), iotest.ErrReader(failure))
+	var results bytes.Buffer
+	summary, err := client.ImportUsers(context.Background(), input, ImportCSV, &results)
+	assert.True(t, errors.Is(err, failure))
+	assert.Equal(t, &ImportSummary{Created: 1}, summary)
+	assert.Len(t, readImportResults(t, &results), 1)
+}
+
+func TestImportUsersCSVMissingColumns(t *testing.T) {
+	var signedUp, updated []string
+	server := newImportServer(t, &signedUp, &updated)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	// The header is checked before any row is imported
+	input := strings.NewReader("mail,pass\na@example.com,secret\nb@example.com,secret\n")
+	var results bytes.Buffer
+	summary, err := client.ImportUsers(context.Background(), input, ImportCSV, &results)
+	assert.Error(t, err)
+	assert.Equal(t, "e021", err.(*Error).ID)
+	assert.Contains(t, err.Error(), "email, password")
+	assert.Equal(t, &ImportSummary{}, summary)
+	assert.Empty(t, results.String())
+	assert.Empty(t, signedUp)
+}
+
+func TestImportUsersUnknownFormat(t *testing.T) {
+	client := newUsersClient("http://localhost")
+	var results bytes.Buffer
+	_, err := client.ImportUsers(context.Background(), strings.NewReader(""), ImportFormat(9), &results)
+	assert.Error(t, err)
+	assert.Equal(t, "e014", err.(*Error).ID)
+}
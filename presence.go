@@ -0,0 +1,295 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultHeartbeatInterval is how often a member announces it is still present
+	DefaultHeartbeatInterval = 10 * time.Second
+	// DefaultPresenceTimeout is how long a member is kept without announcing itself before it is treated as having left
+	DefaultPresenceTimeout = 30 * time.Second
+)
+
+// PresenceAction is the change to the members of a channel reported by a PresenceEvent
+type PresenceAction string
+
+const (
+	// PresenceJoined is reported when a member is first seen
+	PresenceJoined PresenceAction = "join"
+	// PresenceLeft is reported when a member leaves or has not been seen within the timeout
+	PresenceLeft PresenceAction = "leave"
+	// presenceHeartbeat is announced by a member to show it is still present
+	presenceHeartbeat PresenceAction = "heartbeat"
+)
+
+// presenceAnnouncement is the payload a member publishes to the channel
+type presenceAnnouncement struct {
+	Presence PresenceAction  `json:"presence"`
+	MemberID string          `json:"member_id"`
+	State    json.RawMessage `json:"state,omitempty"`
+}
+
+// Member is a client present on a channel
+type Member struct {
+	ID string
+	// State is the value the member joined with, such as a display name or cursor position
+	State json.RawMessage
+	// LastSeen is when the member last announced itself
+	LastSeen time.Time
+}
+
+// PresenceEvent is a member joining or leaving a channel
+type PresenceEvent struct {
+	Action PresenceAction
+	Member Member
+}
+
+// PresenceOption allows presence to be configured with different options.
+type PresenceOption func(*presenceConfig)
+
+// presenceConfig holds the values set by each PresenceOption
+type presenceConfig struct {
+	heartbeat time.Duration
+	timeout   time.Duration
+	state     interface{}
+}
+
+// WithHeartbeatInterval sets how often the member announces it is still present, DefaultHeartbeatInterval is used if it is not positive
+func WithHeartbeatInterval(interval time.Duration) PresenceOption {
+	return func(p *presenceConfig) {
+		p.heartbeat = interval
+	}
+}
+
+// WithPresenceTimeout sets how long other members are kept without announcing themselves, it should be a few heartbeats long
+// DefaultPresenceTimeout is used if it is not positive
+func WithPresenceTimeout(timeout time.Duration) PresenceOption {
+	return func(p *presenceConfig) {
+		p.timeout = timeout
+	}
+}
+
+// WithMemberState sets the value other members see for this member, it can be any value which marshals into JSON
+func WithMemberState(state interface{}) PresenceOption {
+	return func(p *presenceConfig) {
+		p.state = state
+	}
+}
+
+// Presence tracks the members of a channel, announcing this client as one of them
+type Presence struct {
+	// dropped is first so it is aligned for atomic access on 32 bit platforms
+	dropped uint64
+	channel *Channel
+	self    Member
+	config  presenceConfig
+	members map[string]Member
+	mux     sync.Mutex
+	// Events receives each member joining or leaving, it is closed once the presence ends
+	Events <-chan PresenceEvent
+	events chan PresenceEvent
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Join announces the member on the channel and tracks the other members present until the context ends or Leave is called
+// Other members are told to announce themselves when a member joins, so the member set fills in without waiting for a heartbeat
+// Events is buffered, once it is full the oldest event is dropped so tracking never waits for Events to be read, see Dropped
+func (ch *Channel) Join(ctx context.Context, memberID string, opts ...PresenceOption) (*Presence, error) {
+	config := presenceConfig{
+		heartbeat: DefaultHeartbeatInterval,
+		timeout:   DefaultPresenceTimeout,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	if config.heartbeat <= 0 {
+		config.heartbeat = DefaultHeartbeatInterval
+	}
+	if config.timeout <= 0 {
+		config.timeout = DefaultPresenceTimeout
+	}
+	var state json.RawMessage
+	if config.state != nil {
+		var err error
+		state, err = marshal(config.state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	// Announcements which are dropped are made up for by the next heartbeat of the member
+	messages, err := ch.Subscribe(ctx, WithOverflowPolicy(OverflowDropOldest))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	events := make(chan PresenceEvent, DefaultSubscriptionBuffer)
+	p := &Presence{
+		channel: ch,
+		self:    Member{ID: memberID, State: state, LastSeen: time.Now()},
+		config:  config,
+		members: make(map[string]Member),
+		Events:  events,
+		events:  events,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	p.members[memberID] = p.self
+
+	err = p.announce(ctx, PresenceJoined)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go p.heartbeat(ctx)
+	go p.track(ctx, messages.Messages)
+	return p, nil
+}
+
+// Members returns the members present on the channel, including this one, ordered by their id
+func (p *Presence) Members() []Member {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	members := make([]Member, 0, len(p.members))
+	for _, member := range p.members {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ID < members[j].ID
+	})
+	return members
+}
+
+// Dropped returns the number of events discarded as Events was full
+func (p *Presence) Dropped() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// Leave announces the member has left and stops tracking the channel, closing Events
+func (p *Presence) Leave() {
+	p.cancel()
+	<-p.done
+}
+
+// announce publishes the presence of this member
+func (p *Presence) announce(ctx context.Context, action PresenceAction) error {
+	return p.channel.Publish(ctx, presenceAnnouncement{
+		Presence: action,
+		MemberID: p.self.ID,
+		State:    p.self.State,
+	})
+}
+
+// heartbeat announces the member is still present until the context ends
+func (p *Presence) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(p.config.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// A heartbeat which could not be sent is made up for by the next
+			p.announce(ctx, presenceHeartbeat)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// track applies the announcements of the other members and expires those which have not been seen, announcing the leave once finished
+func (p *Presence) track(ctx context.Context, messages <-chan ChannelMessage) {
+	defer close(p.done)
+	defer close(p.events)
+	// The context has ended, so the leave is sent without it
+	defer p.announce(context.Background(), PresenceLeft)
+
+	ticker := time.NewTicker(p.config.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			var announcement presenceAnnouncement
+			if message.Decode(&announcement) != nil || announcement.MemberID == "" || announcement.MemberID == p.self.ID {
+				// Not an announcement, such as a message published to the channel by something else
+				continue
+			}
+			p.apply(ctx, announcement)
+		case now := <-ticker.C:
+			p.expire(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// apply updates the member set with an announcement, emitting an event if the member joined or left
+func (p *Presence) apply(ctx context.Context, announcement presenceAnnouncement) {
+	member := Member{
+		ID:       announcement.MemberID,
+		State:    announcement.State,
+		LastSeen: time.Now(),
+	}
+	p.mux.Lock()
+	_, known := p.members[member.ID]
+	if announcement.Presence == PresenceLeft {
+		delete(p.members, member.ID)
+	} else {
+		p.members[member.ID] = member
+	}
+	p.mux.Unlock()
+
+	switch {
+	case announcement.Presence == PresenceLeft && known:
+		p.emit(PresenceLeft, member)
+	case announcement.Presence != PresenceLeft && !known:
+		p.emit(PresenceJoined, member)
+	}
+	if announcement.Presence == PresenceJoined {
+		// The new member does not know about this one yet
+		p.announce(ctx, presenceHeartbeat)
+	}
+}
+
+// expire removes the members which have not been seen within the timeout
+func (p *Presence) expire(now time.Time) {
+	var expired []Member
+	p.mux.Lock()
+	for id, member := range p.members {
+		if id != p.self.ID && now.Sub(member.LastSeen) > p.config.timeout {
+			expired = append(expired, member)
+			delete(p.members, id)
+		}
+	}
+	p.mux.Unlock()
+	for _, member := range expired {
+		p.emit(PresenceLeft, member)
+	}
+}
+
+// emit passes the event on, dropping the oldest in Events to make room if it is full
+// Only tracking sends to Events, so making room is never undone by another emit
+func (p *Presence) emit(action PresenceAction, member Member) {
+	event := PresenceEvent{Action: action, Member: member}
+	for {
+		select {
+		case p.events <- event:
+			return
+		default:
+		}
+		select {
+		case <-p.events:
+			atomic.AddUint64(&p.dropped, 1)
+		default:
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nextPresenceEvent waits for the next join or leave
+func nextPresenceEvent(t *testing.T, presence *Presence) PresenceEvent {
+	select {
+	case event := <-presence.Events:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("No presence event was received")
+		return PresenceEvent{}
+	}
+}
+
+// memberIDs returns the id of each member present
+func memberIDs(presence *Presence) []string {
+	var ids []string
+	for _, member := range presence.Members() {
+		ids = append(ids, member.ID)
+	}
+	return ids
+}
+
+func TestPresence(t *testing.T) {
+	server := newBrokerServer(t)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	var connections []*Realtime
+	for i := 0; i < 3; i++ {
+		realtime, err := client.NewRealtime(context.Background())
+		assert.NoError(t, err)
+		defer realtime.Close()
+		connections = append(connections, realtime)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := []PresenceOption{WithHeartbeatInterval(20 * time.Millisecond), WithPresenceTimeout(100 * time.Millisecond)}
+	alice, err := connections[0].Channel("document").Join(ctx, "alice", append(opts, WithMemberState(map[string]string{"name": "Alice"}))...)
+	assert.NoError(t, err)
+	defer alice.Leave()
+	assert.Equal(t, []string{"alice"}, memberIDs(alice))
+
+	bob, err := connections[1].Channel("document").Join(ctx, "bob", opts...)
+	assert.NoError(t, err)
+	event := nextPresenceEvent(t, alice)
+	assert.Equal(t, PresenceJoined, event.Action)
+	assert.Equal(t, "bob", event.Member.ID)
+	// Bob learns of alice from the heartbeat sent in reply to the join
+	event = nextPresenceEvent(t, bob)
+	assert.Equal(t, PresenceJoined, event.Action)
+	assert.Equal(t, "alice", event.Member.ID)
+	assert.JSONEq(t, `{"name":"Alice"}`, string(event.Member.State))
+	assert.Equal(t, []string{"alice", "bob"}, memberIDs(alice))
+	assert.Equal(t, []string{"alice", "bob"}, memberIDs(bob))
+
+	bob.Leave()
+	event = nextPresenceEvent(t, alice)
+	assert.Equal(t, PresenceLeft, event.Action)
+	assert.Equal(t, "bob", event.Member.ID)
+	_, open := <-bob.Events
+	assert.False(t, open)
+
+	// A member which stops sending heartbeats is expired
+	err = connections[2].Channel("document").Publish(ctx, presenceAnnouncement{Presence: PresenceJoined, MemberID: "carol"})
+	assert.NoError(t, err)
+	event = nextPresenceEvent(t, alice)
+	assert.Equal(t, PresenceJoined, event.Action)
+	assert.Equal(t, "carol", event.Member.ID)
+	start := time.Now()
+	event = nextPresenceEvent(t, alice)
+	assert.Equal(t, PresenceLeft, event.Action)
+	assert.Equal(t, "carol", event.Member.ID)
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+	assert.Equal(t, []string{"alice"}, memberIDs(alice))
+}
+
+func TestPresenceEventsNotRead(t *testing.T) {
+	events := make(chan PresenceEvent, 2)
+	p := &Presence{Events: events, events: events}
+	for _, id := range []string{"alice", "bob", "carol"} {
+		p.emit(PresenceJoined, Member{ID: id})
+	}
+	// Tracking carries on when Events is not read, the oldest event is dropped
+	assert.Equal(t, uint64(1), p.Dropped())
+	assert.Equal(t, "bob", (<-p.Events).Member.ID)
+	assert.Equal(t, "carol", (<-p.Events).Member.ID)
+}
+
+func TestPresenceInvalidIntervals(t *testing.T) {
+	server := newBrokerServer(t)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.NewRealtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	// Non-positive values fall back to the defaults rather than panicking in the background
+	presence, err := realtime.Channel("document").Join(context.Background(), "alice", WithHeartbeatInterval(0), WithPresenceTimeout(-time.Second))
+	assert.NoError(t, err)
+	defer presence.Leave()
+	assert.Equal(t, DefaultHeartbeatInterval, presence.config.heartbeat)
+	assert.Equal(t, DefaultPresenceTimeout, presence.config.timeout)
+}
@@ -0,0 +1,390 @@
+package jexiasdkgo
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Condition is a filter on the records of a dataset or fileset, conditions can be combined using And and Or
+type Condition struct {
+	field    string
+	operator string
+	value    interface{}
+	// Only set when the condition is a combination of two others
+	logical string
+	left    *Condition
+	right   *Condition
+}
+
+// FieldFilter is used to build a condition against a single field
+type FieldFilter struct {
+	name string
+}
+
+// Field starts a condition against the named field, for example: Field("name").IsEqualTo("value")
+func Field(name string) *FieldFilter {
+	return &FieldFilter{name: name}
+}
+
+func (f *FieldFilter) condition(operator string, value interface{}) *Condition {
+	return &Condition{
+		field:    f.name,
+		operator: operator,
+		value:    value,
+	}
+}
+
+// IsEqualTo matches records where the field is equal to the value
+func (f *FieldFilter) IsEqualTo(value interface{}) *Condition {
+	return f.condition("=", value)
+}
+
+// IsDifferentFrom matches records where the field is not equal to the value
+func (f *FieldFilter) IsDifferentFrom(value interface{}) *Condition {
+	return f.condition("!=", value)
+}
+
+// IsGreaterThan matches records where the field is greater than the value
+func (f *FieldFilter) IsGreaterThan(value interface{}) *Condition {
+	return f.condition(">", value)
+}
+
+// IsLessThan matches records where the field is less than the value
+func (f *FieldFilter) IsLessThan(value interface{}) *Condition {
+	return f.condition("<", value)
+}
+
+// IsEqualOrGreaterThan matches records where the field is greater than or equal to the value
+func (f *FieldFilter) IsEqualOrGreaterThan(value interface{}) *Condition {
+	return f.condition(">=", value)
+}
+
+// IsEqualOrLessThan matches records where the field is less than or equal to the value
+func (f *FieldFilter) IsEqualOrLessThan(value interface{}) *Condition {
+	return f.condition("<=", value)
+}
+
+// IsLike matches records where the field matches a SQL like pattern, such as "%value%"
+func (f *FieldFilter) IsLike(pattern string) *Condition {
+	return f.condition("like", pattern)
+}
+
+// SatisfiesRegex matches records where the field matches the regular expression
+func (f *FieldFilter) SatisfiesRegex(pattern string) *Condition {
+	return f.condition("regex", pattern)
+}
+
+// IsInArray matches records where the field is equal to one of the values
+func (f *FieldFilter) IsInArray(values ...interface{}) *Condition {
+	return f.condition("in", values)
+}
+
+// IsNull matches records where the field has no value
+func (f *FieldFilter) IsNull() *Condition {
+	return f.condition("null", true)
+}
+
+// IsNotNull matches records where the field has a value
+func (f *FieldFilter) IsNotNull() *Condition {
+	return f.condition("null", false)
+}
+
+// And combines both conditions so that a record must match both
+func (c *Condition) And(other *Condition) *Condition {
+	return &Condition{logical: "and", left: c, right: other}
+}
+
+// Or combines both conditions so that a record must match at least one
+func (c *Condition) Or(other *Condition) *Condition {
+	return &Condition{logical: "or", left: c, right: other}
+}
+
+// expression converts the condition into the nested array format used by the Jexia API
+// For example: [{"field":"name"},"=","value","and",[{"field":"age"},">",18,"or",{"field":"age"},"null",true]]
+func (c *Condition) expression() []interface{} {
+	if c.logical == "" {
+		return []interface{}{map[string]string{"field": c.field}, c.operator, c.value}
+	}
+	// Combined sides keep their grouping, otherwise "and" would take precedence over an "or" within them
+	expression := c.left.operand()
+	expression = append(expression, c.logical)
+	return append(expression, c.right.operand()...)
+}
+
+// operand returns the expression of one side of a combined condition, nesting it if it is itself combined
+func (c *Condition) operand() []interface{} {
+	if c.logical == "" {
+		return c.expression()
+	}
+	return []interface{}{c.expression()}
+}
+
+// MarshalJSON allows a condition to be sent to the API
+func (c *Condition) MarshalJSON() ([]byte, error) {
+	return marshal(c.expression())
+}
+
+// ErrConditionRequired is returned when records would be changed or removed without a Where option, compare using errors.Is
+// A condition is required so that every record is not affected by mistake
+var ErrConditionRequired = &Error{
+	ID:        "e020",
+	Message:   "A Where option is required to limit the records affected",
+	Origin:    Internal,
+	Temporary: false,
+}
+
+// QueryOption allows a select, update or delete to be limited to certain records
+type QueryOption func(*query)
+
+// query holds the values set by each QueryOption
+type query struct {
+	condition *Condition
+	order     []map[string][]string
+	limit     int
+	offset    int
+	outputs   []string
+}
+
+// Where limits the records to those which match the condition
+func Where(condition *Condition) QueryOption {
+	return func(q *query) {
+		q.condition = condition
+	}
+}
+
+// SortAsc sorts the records by the fields in ascending order, calling it again adds a further sort
+func SortAsc(fields ...string) QueryOption {
+	return func(q *query) {
+		q.order = append(q.order, map[string][]string{"asc": fields})
+	}
+}
+
+// SortDesc sorts the records by the fields in descending order, calling it again adds a further sort
+func SortDesc(fields ...string) QueryOption {
+	return func(q *query) {
+		q.order = append(q.order, map[string][]string{"desc": fields})
+	}
+}
+
+// Limit sets the maximum number of records to return
+func Limit(limit int) QueryOption {
+	return func(q *query) {
+		q.limit = limit
+	}
+}
+
+// Offset skips the given number of records, used alongside Limit for paging
+func Offset(offset int) QueryOption {
+	return func(q *query) {
+		q.offset = offset
+	}
+}
+
+// Outputs limits the fields returned for each record
+func Outputs(fields ...string) QueryOption {
+	return func(q *query) {
+		q.outputs = fields
+	}
+}
+
+// requireCondition returns ErrConditionRequired unless the options include a Where condition
+func requireCondition(opts []QueryOption) error {
+	q := query{}
+	for _, o := range opts {
+		o(&q)
+	}
+	if q.condition == nil {
+		return ErrConditionRequired
+	}
+	return nil
+}
+
+// buildQuery encodes the query options as url query parameters, including the leading '?' when there are any
+func buildQuery(opts []QueryOption) (string, error) {
+	q := query{}
+	for _, o := range opts {
+		o(&q)
+	}
+
+	values := url.Values{}
+	if q.condition != nil {
+		cond, err := marshal(q.condition)
+		if err != nil {
+			return "", err
+		}
+		values.Set("cond", string(cond))
+	}
+	if len(q.order) > 0 {
+		order, err := marshal(q.order)
+		if err != nil {
+			return "", err
+		}
+		values.Set("order", string(order))
+	}
+	if q.limit > 0 || q.offset > 0 {
+		rng, err := marshal(struct {
+			Limit  int `json:"limit,omitempty"`
+			Offset int `json:"offset,omitempty"`
+		}{q.limit, q.offset})
+		if err != nil {
+			return "", err
+		}
+		values.Set("range", string(rng))
+	}
+	if len(q.outputs) > 0 {
+		outputs, err := marshal(q.outputs)
+		if err != nil {
+			return "", err
+		}
+		values.Set("outputs", string(outputs))
+	}
+
+	if len(values) == 0 {
+		return "", nil
+	}
+	return "?" + values.Encode(), nil
+}
+
+// evaluate checks the record against the condition the way the Jexia API would
+// ok is false when the answer is not known, such as when the record lacks a field the condition needs
+func (c *Condition) evaluate(record map[string]interface{}) (matched, ok bool) {
+	if c.logical != "" {
+		left, leftOK := c.left.evaluate(record)
+		right, rightOK := c.right.evaluate(record)
+		if c.logical == "and" {
+			// A single known false is enough to rule the record out
+			if (leftOK && !left) || (rightOK && !right) {
+				return false, true
+			}
+			return left && right, leftOK && rightOK
+		}
+		if (leftOK && left) || (rightOK && right) {
+			return true, true
+		}
+		return false, leftOK && rightOK
+	}
+
+	actual, present := record[c.field]
+	if !present {
+		return false, false
+	}
+	// The value is put through JSON so it compares like for like with the record, such as a time.Time becoming a string
+	var expected interface{}
+	b, err := marshal(c.value)
+	if err != nil || json.Unmarshal(b, &expected) != nil {
+		return false, false
+	}
+
+	switch c.operator {
+	case "=":
+		return reflect.DeepEqual(actual, expected), true
+	case "!=":
+		return !reflect.DeepEqual(actual, expected), true
+	case "<", ">", "<=", ">=":
+		order, ok := compareValues(actual, expected)
+		if !ok {
+			return false, false
+		}
+		switch c.operator {
+		case "<":
+			return order < 0, true
+		case ">":
+			return order > 0, true
+		case "<=":
+			return order <= 0, true
+		default:
+			return order >= 0, true
+		}
+	case "like", "regex":
+		text, isText := actual.(string)
+		pattern, isPattern := expected.(string)
+		if !isText || !isPattern {
+			return false, false
+		}
+		if c.operator == "like" {
+			pattern = likePattern(pattern)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, false
+		}
+		return re.MatchString(text), true
+	case "in":
+		values, isList := expected.([]interface{})
+		if !isList {
+			return false, false
+		}
+		for _, value := range values {
+			if reflect.DeepEqual(actual, value) {
+				return true, true
+			}
+		}
+		return false, true
+	case "null":
+		isNull, isBool := expected.(bool)
+		if !isBool {
+			return false, false
+		}
+		return (actual == nil) == isNull, true
+	}
+	return false, false
+}
+
+// compareValues orders two numbers, times or strings, ok is false if they can not be compared
+func compareValues(a, b interface{}) (order int, ok bool) {
+	switch a := a.(type) {
+	case float64:
+		b, isNumber := b.(float64)
+		if !isNumber {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		b, isText := b.(string)
+		if !isText {
+			return 0, false
+		}
+		// Times are compared as times, as the same moment can be written in different zones
+		aTime, aErr := time.Parse(time.RFC3339Nano, a)
+		bTime, bErr := time.Parse(time.RFC3339Nano, b)
+		if aErr == nil && bErr == nil {
+			switch {
+			case aTime.Before(bTime):
+				return -1, true
+			case aTime.After(bTime):
+				return 1, true
+			}
+			return 0, true
+		}
+		return strings.Compare(a, b), true
+	}
+	return 0, false
+}
+
+// likePattern converts a SQL like pattern into a regular expression, where % matches any text and _ any single character
+func likePattern(like string) string {
+	var pattern strings.Builder
+	pattern.WriteString("(?s)^")
+	for _, r := range like {
+		switch r {
+		case '%':
+			pattern.WriteString(".*")
+		case '_':
+			pattern.WriteString(".")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	pattern.WriteString("$")
+	return pattern.String()
+}
@@ -0,0 +1,121 @@
+package jexiasdkgo
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionMarshal(t *testing.T) {
+	condition := Field("name").IsEqualTo("value")
+
+	actual, err := marshal(condition)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"field":"name"},"=","value"]`, string(actual))
+}
+
+func TestConditionMarshalCombined(t *testing.T) {
+	condition := Field("name").IsLike("%value%").And(
+		Field("age").IsGreaterThan(18).Or(Field("age").IsNull()),
+	)
+
+	actual, err := marshal(condition)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"field":"name"},"like","%value%","and",[{"field":"age"},"\u003e",18,"or",{"field":"age"},"null",true]]`, string(actual))
+}
+
+func TestConditionMarshalGroupedLeft(t *testing.T) {
+	condition := Field("a").IsEqualTo(1).Or(Field("b").IsEqualTo(2)).And(Field("c").IsEqualTo(3))
+
+	actual, err := marshal(condition)
+	assert.NoError(t, err)
+	assert.Equal(t, `[[{"field":"a"},"=",1,"or",{"field":"b"},"=",2],"and",{"field":"c"},"=",3]`, string(actual))
+	// The client side evaluation agrees with the grouping sent to the API
+	matched, ok := condition.evaluate(map[string]interface{}{"a": 1, "b": 0, "c": 0})
+	assert.True(t, ok)
+	assert.False(t, matched)
+}
+
+func TestConditionMarshalInArray(t *testing.T) {
+	condition := Field("id").IsInArray("a", "b").Or(Field("id").IsNotNull())
+
+	actual, err := marshal(condition)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"field":"id"},"in",["a","b"],"or",{"field":"id"},"null",false]`, string(actual))
+}
+
+func TestBuildQueryEmpty(t *testing.T) {
+	query, err := buildQuery(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", query)
+}
+
+func TestBuildQuery(t *testing.T) {
+	query, err := buildQuery([]QueryOption{
+		Where(Field("name").IsDifferentFrom("value")),
+		SortAsc("name"),
+		SortDesc("created_at", "size"),
+		Limit(10),
+		Offset(20),
+		Outputs("id", "name"),
+	})
+	assert.NoError(t, err)
+
+	values, err := url.ParseQuery(query[1:])
+	assert.NoError(t, err)
+	assert.Equal(t, "?", query[:1])
+	assert.Equal(t, `[{"field":"name"},"!=","value"]`, values.Get("cond"))
+	assert.Equal(t, `[{"asc":["name"]},{"desc":["created_at","size"]}]`, values.Get("order"))
+	assert.Equal(t, `{"limit":10,"offset":20}`, values.Get("range"))
+	assert.Equal(t, `["id","name"]`, values.Get("outputs"))
+}
+
+func TestBuildQueryLimitOnly(t *testing.T) {
+	query, err := buildQuery([]QueryOption{Limit(5)})
+	assert.NoError(t, err)
+	assert.Equal(t, "?range=%7B%22limit%22%3A5%7D", query)
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	record := map[string]interface{}{
+		"name":       "Jexia SDK",
+		"age":        float64(21),
+		"tags":       []interface{}{"go"},
+		"deleted_at": nil,
+		"created_at": "2020-10-01T12:00:00Z",
+	}
+	tests := []struct {
+		condition *Condition
+		matched   bool
+		ok        bool
+	}{
+		{Field("name").IsEqualTo("Jexia SDK"), true, true},
+		{Field("name").IsDifferentFrom("Jexia SDK"), false, true},
+		{Field("age").IsGreaterThan(18), true, true},
+		{Field("age").IsLessThan(21), false, true},
+		{Field("age").IsEqualOrLessThan(21), true, true},
+		{Field("age").IsEqualOrGreaterThan("21"), false, false},
+		// 12:30 in CET is before 12:00 in UTC, even though it would sort after it as text
+		{Field("created_at").IsLessThan(time.Date(2020, 10, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))), false, true},
+		{Field("name").IsLike("jexia%"), false, true},
+		{Field("name").IsLike("Jexia _DK"), true, true},
+		{Field("name").SatisfiesRegex("^Jexia"), true, true},
+		{Field("age").IsInArray(20, 21), true, true},
+		{Field("deleted_at").IsNull(), true, true},
+		{Field("name").IsNull(), false, true},
+		{Field("missing").IsEqualTo(1), false, false},
+		// A known result on either side can decide the combination even when the other side is not known
+		{Field("missing").IsEqualTo(1).And(Field("age").IsLessThan(18)), false, true},
+		{Field("missing").IsEqualTo(1).And(Field("age").IsGreaterThan(18)), false, false},
+		{Field("missing").IsEqualTo(1).Or(Field("age").IsGreaterThan(18)), true, true},
+		{Field("age").IsLessThan(18).Or(Field("name").IsLike("%SDK").And(Field("deleted_at").IsNull())), true, true},
+	}
+	for _, test := range tests {
+		matched, ok := test.condition.evaluate(record)
+		expression, _ := test.condition.MarshalJSON()
+		assert.Equal(t, test.matched, matched, string(expression))
+		assert.Equal(t, test.ok, ok, string(expression))
+	}
+}
@@ -0,0 +1,402 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// realtimePath is the path of the realtime (RTC) websocket on the project url
+	realtimePath = "/rtc"
+	// realtimeWriteTimeout is how long a single message may take to be written to the websocket
+	realtimeWriteTimeout = 10 * time.Second
+)
+
+// Message types sent over the realtime websocket
+const (
+	rtcCommand = "command"
+	rtcEvent   = "event"
+)
+
+// rtcMessage is the envelope of every message sent or received over the realtime websocket
+type rtcMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// rtcCommandData is the data of a command sent to the realtime service, such as a subscription
+type rtcCommandData struct {
+	Command   string      `json:"command"`
+	Arguments interface{} `json:"arguments"`
+}
+
+// rtcResource is the resource, such as a dataset, an event happened to
+type rtcResource struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// rtcEventData is the data of an event received from the realtime service
+type rtcEventData struct {
+	// ID identifies a channel message, it is empty for other events
+	ID       string      `json:"id"`
+	Action   string      `json:"action"`
+	Resource rtcResource `json:"resource"`
+	Modifier struct {
+		ID string `json:"id"`
+	} `json:"modifier"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// rtcSubscription is the arguments of the subscribe and unsubscribe commands
+type rtcSubscription struct {
+	Action    []string `json:"action"`
+	Namespace string   `json:"nsp"`
+	// Condition is the marshalled condition the realtime service filters the events by, if there is one
+	Condition json.RawMessage `json:"cond,omitempty"`
+}
+
+// frameKey identifies the frame a subscription shares, subscriptions with different conditions are sent in frames of their own
+type frameKey struct {
+	namespace string
+	condition string
+}
+
+// key returns the frame the subscription shares
+func (s rtcSubscription) key() frameKey {
+	return frameKey{namespace: s.Namespace, condition: string(s.Condition)}
+}
+
+// Realtime is a websocket connection to the realtime service of the project, used to receive events as they happen
+// The connection is authenticated with the access token of the client, which is sent again each time it is refreshed
+type Realtime struct {
+	client *Client
+	config realtimeConfig
+	// ctx is cancelled by Close, stopping any reconnection in progress
+	ctx    context.Context
+	cancel context.CancelFunc
+	// conn is replaced each time the connection is restored, it is guarded by writeMux
+	conn *websocket.Conn
+	// writeMux is held while writing as the websocket only allows one writer at a time
+	writeMux sync.Mutex
+	// removeRefreshListener stops the token being sent again once the connection is closed
+	removeRefreshListener func()
+	subscriptions         map[*subscription]bool
+	// frames counts the subscriptions to each action of each namespace and condition, it is guarded by frameMux
+	frames    map[frameKey]map[string]int
+	frameMux  sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+	mux       sync.Mutex
+}
+
+// NewRealtime opens the realtime websocket of the project, authenticated with the current access token of the client
+// Each call opens a separate connection, use Realtime to share one connection between every subscription of the client
+// The client should already hold a token, see UseAPKToken and UseUMSToken
+// If the connection drops it is restored along with every subscription, see WithReconnectBackoff to configure this
+func (c *Client) NewRealtime(ctx context.Context, opts ...RealtimeOption) (*Realtime, error) {
+	config := newRealtimeConfig(opts)
+	conn, err := c.dialRealtime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r := &Realtime{
+		client:        c,
+		config:        config,
+		conn:          conn,
+		subscriptions: make(map[*subscription]bool),
+		frames:        make(map[frameKey]map[string]int),
+		done:          make(chan struct{}),
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.removeRefreshListener = c.onTokenRefresh(r.sendToken)
+	go r.readLoop(conn)
+	return r, nil
+}
+
+// SetRealtimeOptions sets the options used to open the realtime connection shared by the client, such as WithReconnectBackoff
+// They apply to the connection used by Dataset.Watch and Fileset.Watch and returned by Client.Realtime
+func SetRealtimeOptions(opts ...RealtimeOption) Option {
+	return func(c *Client) {
+		c.realtimeOptions = opts
+	}
+}
+
+// Realtime returns the connection shared by every watch and subscription made through the client, opening it if it is not open
+// Subscriptions to the same actions of a namespace share the subscribe frame, which is only unsubscribed once the last of them ends
+// The connection stays open until CloseRealtime is called, after which the next call opens a new one
+func (c *Client) Realtime(ctx context.Context) (*Realtime, error) {
+	c.realtimeMux.Lock()
+	defer c.realtimeMux.Unlock()
+	r, err := c.openRealtime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.realtimeKept = true
+	return r, nil
+}
+
+// CloseRealtime closes the connection shared by the client if it is open, ending every watch and subscription using it
+func (c *Client) CloseRealtime() error {
+	c.realtimeMux.Lock()
+	r := c.realtime
+	c.realtime = nil
+	c.realtimeUsers = 0
+	c.realtimeKept = false
+	c.realtimeMux.Unlock()
+	if r == nil {
+		return nil
+	}
+	return r.Close()
+}
+
+// openRealtime returns the shared connection, opening it if it is not open, realtimeMux must be held
+func (c *Client) openRealtime(ctx context.Context) (*Realtime, error) {
+	if c.realtime != nil {
+		select {
+		case <-c.realtime.Done():
+		default:
+			return c.realtime, nil
+		}
+	}
+	r, err := c.NewRealtime(ctx, c.realtimeOptions...)
+	if err != nil {
+		return nil, err
+	}
+	c.realtime = r
+	c.realtimeUsers = 0
+	c.realtimeKept = false
+	return r, nil
+}
+
+// acquireRealtime returns the shared connection for a watch, which calls releaseRealtime once it has finished with it
+func (c *Client) acquireRealtime(ctx context.Context) (*Realtime, error) {
+	c.realtimeMux.Lock()
+	defer c.realtimeMux.Unlock()
+	r, err := c.openRealtime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.realtimeUsers++
+	return r, nil
+}
+
+// releaseRealtime closes the shared connection once no watch is using it, unless it was returned by Client.Realtime
+func (c *Client) releaseRealtime(r *Realtime) {
+	c.realtimeMux.Lock()
+	if c.realtime != r {
+		// Already closed, and possibly replaced by a new connection
+		c.realtimeMux.Unlock()
+		return
+	}
+	c.realtimeUsers--
+	if c.realtimeUsers > 0 || c.realtimeKept {
+		c.realtimeMux.Unlock()
+		return
+	}
+	c.realtime = nil
+	c.realtimeMux.Unlock()
+	r.Close()
+}
+
+// realtimeURL converts the project url into the url of the realtime websocket
+func (c *Client) realtimeURL() (string, error) {
+	c.mux.Lock()
+	projectURL := c.projectURL
+	c.mux.Unlock()
+	u, err := url.Parse(projectURL)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + realtimePath
+	query := u.Query()
+	query.Set("access_token", c.GetToken().Access)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// dialRealtime opens an authenticated websocket to the realtime service
+func (c *Client) dialRealtime(ctx context.Context) (*websocket.Conn, error) {
+	rtcURL, err := c.realtimeURL()
+	if err != nil {
+		return nil, realtimeError(err, false)
+	}
+	c.mux.Lock()
+	timeout := c.http.Timeout
+	c.mux.Unlock()
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: timeout,
+	}
+	conn, response, err := dialer.DialContext(ctx, rtcURL, nil)
+	if err != nil {
+		if response != nil {
+			// A rejected handshake, such as an expired token, will not succeed by trying again
+			e := realtimeError(err, response.StatusCode >= 500)
+			e.Status = response.StatusCode
+			return nil, e
+		}
+		return nil, realtimeError(err, true)
+	}
+	return conn, nil
+}
+
+// realtimeError wraps an error from the realtime websocket
+func realtimeError(err error, temporary bool) *Error {
+	return &Error{
+		ID:        "e015",
+		Message:   fmt.Errorf("Realtime connection failed: %w", err).Error(),
+		Origin:    Internal,
+		Temporary: temporary,
+	}
+}
+
+// sendToken authenticates the connection again with a refreshed access token
+func (r *Realtime) sendToken(token Token) {
+	// A failed write closes the read loop, which is reported by Err
+	r.command(context.Background(), "jwt refresh", map[string]string{"token": token.Access})
+}
+
+// command sends a command, such as a subscription, to the realtime service
+func (r *Realtime) command(ctx context.Context, command string, arguments interface{}) error {
+	data, err := marshal(rtcCommandData{
+		Command:   command,
+		Arguments: arguments,
+	})
+	if err != nil {
+		return err
+	}
+	return r.write(ctx, rtcMessage{Type: rtcCommand, Data: data})
+}
+
+// write sends a single message over the websocket, giving up at the deadline of the context if it is sooner than the write timeout
+func (r *Realtime) write(ctx context.Context, message rtcMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(realtimeWriteTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	r.writeMux.Lock()
+	defer r.writeMux.Unlock()
+	r.conn.SetWriteDeadline(deadline)
+	err := r.conn.WriteJSON(message)
+	if err != nil {
+		return realtimeError(err, true)
+	}
+	return nil
+}
+
+// readLoop reads each message from the websocket until it is closed, restoring the connection if it drops
+func (r *Realtime) readLoop(conn *websocket.Conn) {
+	for {
+		var message rtcMessage
+		err := conn.ReadJSON(&message)
+		if err != nil {
+			switch {
+			case r.ctx.Err() != nil:
+				// Closed by Close, which finishes the connection itself
+			case r.config.reconnect:
+				r.reconnect(err)
+			default:
+				r.finish(err)
+			}
+			return
+		}
+		r.dispatch(message)
+	}
+}
+
+// dispatch passes a received message on to whatever is waiting for it
+func (r *Realtime) dispatch(message rtcMessage) {
+	if message.Type != rtcEvent {
+		return
+	}
+	var event rtcEventData
+	if err := json.Unmarshal(message.Data, &event); err != nil {
+		// A malformed event can not be passed on, the connection itself is still fine
+		return
+	}
+	r.mux.Lock()
+	var matched []*subscription
+	for s := range r.subscriptions {
+		if s.matches(event) {
+			matched = append(matched, s)
+		}
+	}
+	r.mux.Unlock()
+	for _, s := range matched {
+		s.deliver(event)
+	}
+}
+
+// finish marks the connection as closed, keeping the first error which caused it
+func (r *Realtime) finish(err error) {
+	r.closeOnce.Do(func() {
+		r.mux.Lock()
+		if e, ok := err.(*Error); ok {
+			r.err = e
+		} else if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			r.err = realtimeError(err, true)
+		}
+		r.mux.Unlock()
+		r.cancel()
+		r.removeRefreshListener()
+		r.writeMux.Lock()
+		r.conn.Close()
+		r.writeMux.Unlock()
+		close(r.done)
+	})
+}
+
+// Done returns a channel which is closed once the connection has closed, whether by Close or an error
+func (r *Realtime) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err returns the error which closed the connection, or nil if it is open or was closed by Close
+func (r *Realtime) Err() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.err
+}
+
+// Close closes the websocket and stops the token being sent on each refresh
+func (r *Realtime) Close() error {
+	select {
+	case <-r.done:
+		return nil
+	default:
+	}
+	// Cancelling first stops a reconnection in progress from replacing the connection
+	r.cancel()
+	r.writeMux.Lock()
+	err := r.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(realtimeWriteTimeout),
+	)
+	r.writeMux.Unlock()
+	r.finish(&websocket.CloseError{Code: websocket.CloseNormalClosure})
+	if err != nil && err != websocket.ErrCloseSent {
+		return realtimeError(err, false)
+	}
+	return nil
+}
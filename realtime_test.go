@@ -0,0 +1,219 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRealtimeServer upgrades requests to the realtime path, passing each message the client sends to received
+// Any other path is treated as a token refresh so RefreshToken can be tested alongside the connection
+func newRealtimeServer(t *testing.T, received chan<- rtcMessage) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/rtc" {
+			assert.Equal(t, "/auth/refresh", req.URL.Path)
+			rw.Write([]byte(`{"access_token":"refreshedAccessToken","refresh_token":"refreshedRefreshToken"}`))
+			return
+		}
+		if req.URL.Query().Get("access_token") != "yourAccessToken" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		for {
+			var message rtcMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				return
+			}
+			received <- message
+		}
+	}))
+}
+
+func newRealtimeClient(url, access string) *Client {
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(url),
+	)
+	client.SetToken(Token{Access: access, Refresh: "yourRefreshToken", Lifetime: time.Hour})
+	return client
+}
+
+func TestRealtimeURL(t *testing.T) {
+	client := NewClient("projectID", "projectZone")
+	client.SetToken(Token{Access: "yourAccessToken"})
+	rtcURL, err := client.realtimeURL()
+	assert.NoError(t, err)
+	assert.Equal(t, "wss://projectID.projectZone.app.jexia.com/rtc?access_token=yourAccessToken", rtcURL)
+}
+
+func TestRealtimeSendsRefreshedToken(t *testing.T) {
+	received := make(chan rtcMessage, 1)
+	server := newRealtimeServer(t, received)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	realtime, err := client.NewRealtime(context.Background())
+	assert.NoError(t, err)
+
+	client.RefreshToken()
+	select {
+	case message := <-received:
+		assert.Equal(t, "command", message.Type)
+		var command struct {
+			Command   string            `json:"command"`
+			Arguments map[string]string `json:"arguments"`
+		}
+		assert.NoError(t, json.Unmarshal(message.Data, &command))
+		assert.Equal(t, "jwt refresh", command.Command)
+		assert.Equal(t, "refreshedAccessToken", command.Arguments["token"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("The refreshed token was not sent")
+	}
+
+	assert.NoError(t, realtime.Close())
+	<-realtime.Done()
+	assert.NoError(t, realtime.Err())
+	// Once closed the token should no longer be sent
+	assert.Empty(t, client.refreshListeners)
+	assert.NoError(t, realtime.Close())
+}
+
+func TestRealtimeRejectedToken(t *testing.T) {
+	server := newRealtimeServer(t, nil)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "expiredAccessToken")
+
+	_, err := client.NewRealtime(context.Background())
+	assert.Error(t, err)
+	e := err.(*Error)
+	assert.Equal(t, "e015", e.ID)
+	assert.Equal(t, http.StatusUnauthorized, e.Status)
+	assert.False(t, e.Temporary)
+}
+
+func TestRealtimeClosedByServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(rw, req, nil)
+		if assert.NoError(t, err) {
+			conn.Close()
+		}
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	realtime, err := client.NewRealtime(context.Background(), WithoutReconnect())
+	assert.NoError(t, err)
+	select {
+	case <-realtime.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("The connection was not closed")
+	}
+	assert.Error(t, realtime.Err())
+	assert.True(t, realtime.Err().(*Error).Temporary)
+}
+
+func TestClientRealtimeShared(t *testing.T) {
+	var mux sync.Mutex
+	connections := 0
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		mux.Lock()
+		connections++
+		mux.Unlock()
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	first, err := client.Realtime(context.Background())
+	assert.NoError(t, err)
+	second, err := client.Realtime(context.Background())
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+
+	// Once closed a new connection is opened
+	assert.NoError(t, first.Close())
+	third, err := client.Realtime(context.Background())
+	assert.NoError(t, err)
+	defer third.Close()
+	assert.NotSame(t, first, third)
+	mux.Lock()
+	assert.Equal(t, 2, connections)
+	mux.Unlock()
+}
+
+func TestSharedRealtimeClosedOnceUnused(t *testing.T) {
+	closed := make(chan bool, 2)
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.Equal(t, "unsubscribe", readCommand(t, conn, &arguments))
+		_, _, err := conn.ReadMessage()
+		closed <- websocket.IsCloseError(err, websocket.CloseNormalClosure)
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		watch, err := client.GetDataset("posts").Watch(ctx)
+		assert.NoError(t, err)
+		cancel()
+		for range watch.Events {
+		}
+		// Once the only watch has ended the connection is closed, the next watch opens another
+		select {
+		case normal := <-closed:
+			assert.True(t, normal)
+		case <-time.After(2 * time.Second):
+			t.Fatal("The connection was not closed")
+		}
+	}
+	assert.Nil(t, client.realtime)
+}
+
+func TestCloseRealtime(t *testing.T) {
+	// Nothing is open, so nothing is dialled
+	client := newRealtimeClient("http://localhost:1", "yourAccessToken")
+	assert.NoError(t, client.CloseRealtime())
+
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client = NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+		SetRealtimeOptions(WithoutReconnect()),
+	)
+	realtime, err := client.Realtime(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, realtime.config.reconnect)
+	assert.NoError(t, client.CloseRealtime())
+	select {
+	case <-realtime.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("The connection was not closed")
+	}
+	assert.NoError(t, realtime.Err())
+}
@@ -0,0 +1,223 @@
+package jexiasdkgo
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// DefaultReconnectDelay is how long to wait before the first attempt to restore a dropped realtime connection
+	DefaultReconnectDelay = 500 * time.Millisecond
+	// DefaultMaxReconnectDelay is the longest wait between attempts, the wait doubles after each failed attempt until then
+	DefaultMaxReconnectDelay = 30 * time.Second
+	// minReconnectDelay is the shortest wait between attempts, so a zero delay does not redial the service in a tight loop
+	minReconnectDelay = 10 * time.Millisecond
+	// replayPageSize is the number of missed channel messages fetched at a time when a connection is restored
+	replayPageSize = 100
+)
+
+// RealtimeOption allows a realtime connection to be configured with different options.
+type RealtimeOption func(*realtimeConfig)
+
+// realtimeConfig holds the values set by each RealtimeOption
+type realtimeConfig struct {
+	reconnect         bool
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+	// maxReconnectAttempts is the number of failed attempts in a row before giving up, 0 never gives up
+	maxReconnectAttempts int
+}
+
+// newRealtimeConfig applies the options over the defaults, keeping the waits between attempts from being too short
+func newRealtimeConfig(opts []RealtimeOption) realtimeConfig {
+	config := realtimeConfig{
+		reconnect:         true,
+		reconnectDelay:    DefaultReconnectDelay,
+		maxReconnectDelay: DefaultMaxReconnectDelay,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	if config.reconnectDelay < minReconnectDelay {
+		config.reconnectDelay = minReconnectDelay
+	}
+	if config.maxReconnectDelay < config.reconnectDelay {
+		config.maxReconnectDelay = config.reconnectDelay
+	}
+	return config
+}
+
+// WithReconnectBackoff sets the wait before the first attempt to restore a dropped connection and the longest wait between attempts
+// Waits shorter than 10ms are lengthened, and the longest wait is never shorter than the first
+func WithReconnectBackoff(delay, maxDelay time.Duration) RealtimeOption {
+	return func(r *realtimeConfig) {
+		r.reconnectDelay = delay
+		r.maxReconnectDelay = maxDelay
+	}
+}
+
+// WithReconnectAttempts gives up restoring a dropped connection after the number of failed attempts in a row
+// Once given up the connection is closed, see Done and Err
+func WithReconnectAttempts(attempts int) RealtimeOption {
+	return func(r *realtimeConfig) {
+		r.maxReconnectAttempts = attempts
+	}
+}
+
+// WithoutReconnect closes the connection when it drops rather than restoring it
+func WithoutReconnect() RealtimeOption {
+	return func(r *realtimeConfig) {
+		r.reconnect = false
+	}
+}
+
+// reconnect restores a dropped connection, waiting longer after each failed attempt
+// Once connected every subscription is made again and channel messages published while disconnected are replayed
+func (r *Realtime) reconnect(cause error) {
+	delay := r.config.reconnectDelay
+	for attempt := 1; r.config.maxReconnectAttempts == 0 || attempt <= r.config.maxReconnectAttempts; attempt++ {
+		select {
+		case <-time.After(jitter(delay)):
+		case <-r.ctx.Done():
+			return
+		}
+		delay *= 2
+		if delay > r.config.maxReconnectDelay {
+			delay = r.config.maxReconnectDelay
+		}
+
+		conn, err := r.client.dialRealtime(r.ctx)
+		if err != nil {
+			cause = err
+			if e, ok := err.(*Error); ok && (e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden) {
+				// The token may have expired while disconnected, the next attempt uses the refreshed token
+				r.client.refreshToken()
+			}
+			continue
+		}
+		if !r.replaceConn(conn) {
+			return
+		}
+		// A failed write means the new connection has dropped as well, reading from it starts the next reconnection
+		r.restoreSubscriptions()
+		go r.readLoop(conn)
+		return
+	}
+	r.finish(cause)
+}
+
+// jitter randomises the delay to between half and all of it, so many clients do not reconnect at the same moment
+func jitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// replaceConn swaps in the restored connection, returning false if Close was called while reconnecting
+func (r *Realtime) replaceConn(conn *websocket.Conn) bool {
+	r.writeMux.Lock()
+	defer r.writeMux.Unlock()
+	if r.ctx.Err() != nil {
+		conn.Close()
+		return false
+	}
+	r.conn.Close()
+	r.conn = conn
+	return true
+}
+
+// restoreSubscriptions sends every subscribe frame again on the restored connection, then replays the channel messages missed
+// This is done before reading from the connection, so live messages are passed on after the replayed ones
+func (r *Realtime) restoreSubscriptions() error {
+	r.mux.Lock()
+	subscriptions := make([]*subscription, 0, len(r.subscriptions))
+	for s := range r.subscriptions {
+		subscriptions = append(subscriptions, s)
+	}
+	r.mux.Unlock()
+
+	// Each frame is subscribed once with every action in use, those no longer counted were unsubscribed since
+	r.frameMux.Lock()
+	sent := make(map[frameKey]bool)
+	for _, s := range subscriptions {
+		key := s.key()
+		if sent[key] || len(r.frames[key]) == 0 {
+			continue
+		}
+		sent[key] = true
+		err := r.command(r.ctx, "subscribe", rtcSubscription{
+			Action:    r.activeActions(key, s.Action),
+			Namespace: s.Namespace,
+			Condition: s.Condition,
+		})
+		if err != nil {
+			r.frameMux.Unlock()
+			return err
+		}
+	}
+	r.frameMux.Unlock()
+	for _, s := range subscriptions {
+		if s.resource.Type == "channel" {
+			r.replay(s)
+		}
+	}
+	return nil
+}
+
+// replay passes on the channel messages published since the last one the subscription received
+// It goes back as far as the ids of messages are remembered, so messages published out of order while disconnected are not missed
+// Channels without history return an error, in which case there is nothing to replay
+func (r *Realtime) replay(s *subscription) {
+	s.seenMux.Lock()
+	last := s.last
+	s.seenMux.Unlock()
+	since := s.since
+	if !last.IsZero() {
+		// Messages from before the subscription are not replayed, unless the clock of the publisher is behind
+		earliest := s.since
+		if last.Before(earliest) {
+			earliest = last
+		}
+		since = last.Add(-seenWindow)
+		if since.Before(earliest) {
+			since = earliest
+		}
+	}
+	channel := r.Channel(s.resource.Name)
+	for {
+		messages, err := channel.History(r.ctx, Since(since), HistoryLimit(replayPageSize))
+		if err != nil {
+			return
+		}
+		start := since
+		for _, message := range messages {
+			event := rtcEventData{
+				ID:        message.ID,
+				Action:    actionPublished,
+				Resource:  s.resource,
+				Timestamp: message.Timestamp,
+				Data:      message.Data,
+			}
+			event.Modifier.ID = message.SenderID
+			if s.accept(event) {
+				s.push(event)
+			}
+			since = message.Timestamp
+		}
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		// A short page means the history has been read up to the live messages
+		// A full page published at a single moment can not be paged past, as the next page would start at the same time
+		if len(messages) < replayPageSize || !since.After(start) {
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		delay := jitter(time.Second)
+		assert.True(t, delay >= 500*time.Millisecond && delay <= time.Second, "delay should be between half and all of the backoff")
+	}
+	assert.Equal(t, time.Duration(0), jitter(0))
+}
+
+func TestRealtimeConfigBackoff(t *testing.T) {
+	config := newRealtimeConfig(nil)
+	assert.Equal(t, DefaultReconnectDelay, config.reconnectDelay)
+	assert.Equal(t, DefaultMaxReconnectDelay, config.maxReconnectDelay)
+
+	// A zero backoff would redial in a tight loop, as doubling it never lengthens the wait
+	config = newRealtimeConfig([]RealtimeOption{WithReconnectBackoff(0, 0)})
+	assert.Equal(t, minReconnectDelay, config.reconnectDelay)
+	assert.Equal(t, minReconnectDelay, config.maxReconnectDelay)
+
+	config = newRealtimeConfig([]RealtimeOption{WithReconnectBackoff(time.Second, time.Millisecond)})
+	assert.Equal(t, time.Second, config.reconnectDelay)
+	assert.Equal(t, time.Second, config.maxReconnectDelay)
+}
+
+func TestRealtimeReconnectReplaysChannel(t *testing.T) {
+	var mux sync.Mutex
+	connections := 0
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/auth/refresh":
+			rw.Write([]byte(`{"access_token":"refreshedAccessToken","refresh_token":"refreshedRefreshToken"}`))
+			return
+		case "/channel/notifications":
+			assert.Equal(t, "Bearer refreshedAccessToken", req.Header.Get("Authorization"))
+			assert.Contains(t, req.URL.Query().Get("cond"), "2020-10-01T12:00:00Z")
+			// The history overlaps with the messages already received and those sent live once reconnected
+			rw.Write([]byte(`[
+				{"id":"m1","created_at":"2020-10-01T12:00:00Z","data":1},
+				{"id":"m2","created_at":"2020-10-01T12:01:00Z","data":2}
+			]`))
+			return
+		}
+
+		mux.Lock()
+		connections++
+		connection := connections
+		mux.Unlock()
+		token := req.URL.Query().Get("access_token")
+		if connection == 2 {
+			// The token expired while disconnected
+			assert.Equal(t, "yourAccessToken", token)
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.Equal(t, "notifications", arguments.Namespace)
+		if connection == 1 {
+			writeEvent(t, conn, `{"id":"m1","action":"published","resource":{"type":"channel","name":"notifications"},"timestamp":"2020-10-01T12:00:00Z","data":1}`)
+			// Wait for the message to be read before dropping the connection
+			time.Sleep(100 * time.Millisecond)
+			return
+		}
+		assert.Equal(t, "refreshedAccessToken", token)
+		writeEvent(t, conn, `{"id":"m2","action":"published","resource":{"type":"channel","name":"notifications"},"timestamp":"2020-10-01T12:01:00Z","data":2}`)
+		writeEvent(t, conn, `{"id":"m3","action":"published","resource":{"type":"channel","name":"notifications"},"timestamp":"2020-10-01T12:02:00Z","data":3}`)
+		conn.ReadMessage()
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.NewRealtime(context.Background(), WithReconnectBackoff(10*time.Millisecond, 50*time.Millisecond))
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	subscription, err := realtime.Channel("notifications").Subscribe(context.Background())
+	assert.NoError(t, err)
+
+	var ids []string
+	for len(ids) < 3 {
+		select {
+		case message := <-subscription.Messages:
+			ids = append(ids, message.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Only received %v", ids)
+		}
+	}
+	assert.Equal(t, []string{"m1", "m2", "m3"}, ids)
+	select {
+	case message := <-subscription.Messages:
+		t.Fatalf("Received %v twice", message.ID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRealtimeGivesUpReconnecting(t *testing.T) {
+	var mux sync.Mutex
+	connections := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mux.Lock()
+		connections++
+		connection := connections
+		mux.Unlock()
+		if connection > 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := (&websocket.Upgrader{}).Upgrade(rw, req, nil)
+		if assert.NoError(t, err) {
+			conn.Close()
+		}
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	realtime, err := client.NewRealtime(
+		context.Background(),
+		WithReconnectBackoff(time.Millisecond, 5*time.Millisecond),
+		WithReconnectAttempts(3),
+	)
+	assert.NoError(t, err)
+	select {
+	case <-realtime.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("The connection was not closed")
+	}
+	mux.Lock()
+	assert.Equal(t, 4, connections)
+	mux.Unlock()
+	e := realtime.Err().(*Error)
+	assert.Equal(t, "e015", e.ID)
+	assert.Equal(t, http.StatusServiceUnavailable, e.Status)
+}
@@ -2,8 +2,12 @@ package jexiasdkgo
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 )
 
@@ -17,6 +21,20 @@ func addHeader(key, value string) requestOption {
 	}
 }
 
+// setHeader replaces any existing values of a header, such as the default 'Content-Type'
+func setHeader(key, value string) requestOption {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
+// setContext attaches a context to the request so it can be cancelled or given a deadline
+func setContext(ctx context.Context) requestOption {
+	return func(r *http.Request) {
+		*r = *r.WithContext(ctx)
+	}
+}
+
 // setBody sets the body of the request when we are making calls such as a http post
 func setBody(body []byte) requestOption {
 	return func(r *http.Request) {
@@ -27,6 +45,20 @@ func setBody(body []byte) requestOption {
 	}
 }
 
+// setBodyReader streams the body of the request from a reader when the length is not known up front
+func setBodyReader(body io.Reader) requestOption {
+	return func(r *http.Request) {
+		rc, ok := body.(io.ReadCloser)
+		if !ok {
+			rc = ioutil.NopCloser(body)
+		}
+		r.Body = rc
+		// A length of -1 marks the body as unknown so it is sent chunked, the body can also not be replayed
+		r.ContentLength = -1
+		r.GetBody = nil
+	}
+}
+
 // useAPKMethod is a short-hand function for making calls using the APK Jexia method
 func useAPKMethod(key, secret string) requestOption {
 	return func(r *http.Request) {
@@ -65,14 +97,14 @@ func (c *Client) buildRequest(method, url string, opts ...requestOption) (*http.
 
 // executeRequest calls the http.Do function
 func (c *Client) executeRequest(req *http.Request, target interface{}) error {
-	resp, err := c.http.Do(req)
+	return c.executeRequestWith(c.http, req, target)
+}
+
+// executeRequestWith calls the http.Do function of the given http client, such as one with a different timeout
+func (c *Client) executeRequestWith(client *http.Client, req *http.Request, target interface{}) error {
+	resp, err := client.Do(req)
 	if err != nil {
-		return &Error{
-			ID:        "e005",
-			Message:   fmt.Errorf("Unable to execute http request: %w", err).Error(),
-			Origin:    Internal,
-			Temporary: false,
-		}
+		return requestError(err)
 	}
 
 	defer resp.Body.Close()
@@ -89,6 +121,43 @@ func (c *Client) executeRequest(req *http.Request, target interface{}) error {
 	return unmarshal(b, &target)
 }
 
+// requestError is the error returned when a request could not be made at all
+// Timeouts are marked as temporary as the same request may well succeed if tried again
+func requestError(err error) *Error {
+	var netErr net.Error
+	return &Error{
+		ID:        "e005",
+		Message:   fmt.Errorf("Unable to execute http request: %w", err).Error(),
+		Origin:    Internal,
+		Temporary: errors.As(err, &netErr) && netErr.Timeout(),
+	}
+}
+
+// untimedHTTP returns a copy of the http client without its timeout, for bodies which may take longer to send or receive
+// The context of the request is relied on to end it instead
+func (c *Client) untimedHTTP() *http.Client {
+	client := *c.http
+	client.Timeout = 0
+	return &client
+}
+
+// stream calls the http.Do function but leaves the response body open so it can be read as it arrives
+// The client timeout would cut the body off partway, so only the context of the request can end it
+// The caller is responsible for closing the body of the returned response
+func (c *Client) stream(req *http.Request) (*http.Response, error) {
+	resp, err := c.untimedHTTP().Do(req)
+	if err != nil {
+		return nil, requestError(err)
+	}
+
+	err = checkForAPIError(resp)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
 // get performs a http get request
 func (c *Client) get(url string, target interface{}, opts ...requestOption) error {
 	req, err := c.buildRequest(http.MethodGet, url, opts...)
@@ -115,3 +184,21 @@ func (c *Client) post(url string, target interface{}, opts ...requestOption) err
 	}
 	return c.executeRequest(req, target)
 }
+
+// delete performs a http delete request for removing data
+func (c *Client) delete(url string, target interface{}, opts ...requestOption) error {
+	req, err := c.buildRequest(http.MethodDelete, url, opts...)
+	if err != nil {
+		return err
+	}
+	return c.executeRequest(req, target)
+}
+
+// getStream performs a http get request, returning the response so that the body can be streamed
+func (c *Client) getStream(url string, opts ...requestOption) (*http.Response, error) {
+	req, err := c.buildRequest(http.MethodGet, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.stream(req)
+}
@@ -2,6 +2,7 @@ package jexiasdkgo
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -71,3 +72,41 @@ func TestAddToken(t *testing.T) {
 	option(request)
 	assert.Equal(t, request.Header, http.Header(http.Header{"Authorization": {fmt.Sprintf("Bearer %v", accessToken)}}))
 }
+
+func TestSetHeader(t *testing.T) {
+	var request *http.Request
+	request = &http.Request{
+		Header: http.Header{"Content-Type": {"application/json"}},
+	}
+	option := setHeader("Content-Type", "text/plain")
+
+	option(request)
+	assert.Equal(t, request.Header, http.Header(http.Header{"Content-Type": {"text/plain"}}))
+}
+
+func TestSetBodyReader(t *testing.T) {
+	var request *http.Request
+	request = &http.Request{}
+
+	option := setBodyReader(bytes.NewReader([]byte("test body")))
+
+	option(request)
+	assert.Equal(t, int64(-1), request.ContentLength)
+	assert.Nil(t, request.GetBody)
+	body, err := ioutil.ReadAll(request.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "test body", string(body))
+}
+
+func TestSetContext(t *testing.T) {
+	type key string
+	request, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	assert.NoError(t, err)
+	ctx := context.WithValue(context.Background(), key("test"), "value")
+
+	option := setContext(ctx)
+
+	assert.NotEqual(t, ctx, request.Context())
+	option(request)
+	assert.Equal(t, ctx, request.Context())
+}
@@ -0,0 +1,271 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCallTimeout is how long Call waits for a response when the context has no deadline
+const DefaultCallTimeout = 30 * time.Second
+
+// ErrCallTimeout is returned by Call when no response arrives in time, compare using errors.Is
+var ErrCallTimeout = &Error{
+	ID:        "e018",
+	Message:   "No response to the call was received in time",
+	Origin:    Internal,
+	Temporary: true,
+}
+
+// ErrCallFailed is returned by Call when the handler of the method returned an error, compare using errors.Is
+var ErrCallFailed = &Error{
+	ID:        "e019",
+	Message:   "Call failed",
+	Origin:    API,
+	Temporary: false,
+}
+
+// rpcEnvelope is the payload published for both requests and responses
+type rpcEnvelope struct {
+	// ID correlates a response with its request
+	ID      string          `json:"id"`
+	Method  string          `json:"method,omitempty"`
+	ReplyTo string          `json:"reply_to,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// newCorrelationID returns a random id for a request or reply channel
+func newCorrelationID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RPCClient calls methods handled by an RPCServer listening on the same channel
+type RPCClient struct {
+	channel *Channel
+	replyTo string
+	replies *ChannelSubscription
+	timeout time.Duration
+	pending map[string]chan rpcEnvelope
+	mux     sync.Mutex
+	cancel  context.CancelFunc
+}
+
+// NewRPCClient subscribes to a reply channel of its own, ready for calls to be made to the methods served on this channel
+// The client stops receiving replies once the context ends or Close is called
+func (ch *Channel) NewRPCClient(ctx context.Context) (*RPCClient, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	replyTo := fmt.Sprintf("%v.reply.%v", ch.Name, newCorrelationID())
+	replies, err := ch.realtime.Channel(replyTo).Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c := &RPCClient{
+		channel: ch,
+		replyTo: replyTo,
+		replies: replies,
+		timeout: DefaultCallTimeout,
+		pending: make(map[string]chan rpcEnvelope),
+		cancel:  cancel,
+	}
+	go c.receive()
+	return c, nil
+}
+
+// SetTimeout sets how long Call waits for a response when the context has no deadline
+func (c *RPCClient) SetTimeout(timeout time.Duration) {
+	c.mux.Lock()
+	c.timeout = timeout
+	c.mux.Unlock()
+}
+
+// receive passes each response on to the call waiting for it, responses to calls which have given up are discarded
+func (c *RPCClient) receive() {
+	for message := range c.replies.Messages {
+		var response rpcEnvelope
+		if message.Decode(&response) != nil {
+			continue
+		}
+		c.mux.Lock()
+		waiting, ok := c.pending[response.ID]
+		delete(c.pending, response.ID)
+		c.mux.Unlock()
+		if ok {
+			waiting <- response
+		}
+	}
+	// The replies will not arrive once the subscription has ended, so the calls waiting are released
+	c.mux.Lock()
+	for id, waiting := range c.pending {
+		close(waiting)
+		delete(c.pending, id)
+	}
+	c.mux.Unlock()
+}
+
+// Call publishes a request for the method and waits for its response, decoding the result into the target if it is not nil
+// An error matching ErrCallTimeout is returned if no response arrives before the context ends or the timeout passes
+// An error matching ErrCallFailed is returned if the handler of the method returned an error
+func (c *RPCClient) Call(ctx context.Context, method string, params interface{}, target interface{}) error {
+	c.mux.Lock()
+	timeout := c.timeout
+	c.mux.Unlock()
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	payload, err := marshal(params)
+	if err != nil {
+		return err
+	}
+	request := rpcEnvelope{
+		ID:      newCorrelationID(),
+		Method:  method,
+		ReplyTo: c.replyTo,
+		Params:  payload,
+	}
+	waiting := make(chan rpcEnvelope, 1)
+	c.mux.Lock()
+	c.pending[request.ID] = waiting
+	c.mux.Unlock()
+	defer func() {
+		c.mux.Lock()
+		delete(c.pending, request.ID)
+		c.mux.Unlock()
+	}()
+
+	err = c.channel.Publish(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case response, ok := <-waiting:
+		if !ok {
+			return realtimeError(fmt.Errorf("reply channel closed"), true)
+		}
+		if response.Error != "" {
+			return &Error{
+				ID:        ErrCallFailed.ID,
+				Message:   fmt.Sprintf("%v: %v: %v", ErrCallFailed.Message, method, response.Error),
+				Origin:    API,
+				Temporary: false,
+			}
+		}
+		if target == nil || len(response.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(response.Result, target)
+	case <-ctx.Done():
+		return &Error{
+			ID:        ErrCallTimeout.ID,
+			Message:   fmt.Sprintf("%v: %v: %v", ErrCallTimeout.Message, method, ctx.Err()),
+			Origin:    Internal,
+			Temporary: true,
+		}
+	}
+}
+
+// Close stops receiving replies, any calls still waiting return an error
+func (c *RPCClient) Close() {
+	c.cancel()
+}
+
+// RPCHandler handles a call to a method, the params are as they were passed to Call
+// The returned result is sent back to the caller, or the message of the error if there is one
+type RPCHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// RPCServer answers the calls published to a channel by passing them to the handler registered for their method
+// Every server subscribed to the channel answers each call, the caller uses the first response to arrive
+type RPCServer struct {
+	channel  *Channel
+	requests *ChannelSubscription
+	handlers map[string]RPCHandler
+	mux      sync.Mutex
+	cancel   context.CancelFunc
+}
+
+// NewRPCServer subscribes to the calls published to the channel, register handlers with Handle then call Serve
+// Calls made once it returns are held until Serve answers them, the server stops receiving calls once the context ends or Close is called
+func (ch *Channel) NewRPCServer(ctx context.Context) (*RPCServer, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	requests, err := ch.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &RPCServer{
+		channel:  ch,
+		requests: requests,
+		handlers: make(map[string]RPCHandler),
+		cancel:   cancel,
+	}, nil
+}
+
+// Handle registers the handler for the method, replacing any handler already registered for it
+func (s *RPCServer) Handle(method string, handler RPCHandler) {
+	s.mux.Lock()
+	s.handlers[method] = handler
+	s.mux.Unlock()
+}
+
+// Serve answers calls until the context ends or Close is called, each call is handled in its own goroutine
+// It returns nil once the server is stopped, or the error which closed the realtime connection
+func (s *RPCServer) Serve(ctx context.Context) error {
+	var handling sync.WaitGroup
+	defer handling.Wait()
+	for {
+		select {
+		case message, ok := <-s.requests.Messages:
+			if !ok {
+				// Nil if the server was closed rather than the connection
+				return s.channel.realtime.Err()
+			}
+			var request rpcEnvelope
+			if message.Decode(&request) != nil || request.Method == "" || request.ReplyTo == "" {
+				// Not a call, such as a message published to the channel by something else
+				continue
+			}
+			handling.Add(1)
+			go func() {
+				defer handling.Done()
+				s.answer(ctx, request)
+			}()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Close stops receiving calls, ending Serve once the calls being handled have been answered
+func (s *RPCServer) Close() {
+	s.cancel()
+}
+
+// answer runs the handler for the request and publishes its response to the reply channel
+func (s *RPCServer) answer(ctx context.Context, request rpcEnvelope) {
+	s.mux.Lock()
+	handler, ok := s.handlers[request.Method]
+	s.mux.Unlock()
+
+	response := rpcEnvelope{ID: request.ID}
+	if !ok {
+		response.Error = fmt.Sprintf("unknown method %v", request.Method)
+	} else if result, err := handler(ctx, request.Params); err != nil {
+		response.Error = err.Error()
+	} else if response.Result, err = marshal(result); err != nil {
+		response.Error = err.Error()
+	}
+	// The caller times out if the response can not be published, so there is no one to report the error to
+	s.channel.realtime.Channel(request.ReplyTo).Publish(ctx, response)
+}
@@ -0,0 +1,155 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBrokerServer passes each message published to a channel on as an event to every connection subscribed to it
+func newBrokerServer(t *testing.T) *httptest.Server {
+	var mux sync.Mutex
+	subscribed := make(map[*websocket.Conn]map[string]bool)
+	id := 0
+	return newWebsocketServer(t, func(conn *websocket.Conn) {
+		mux.Lock()
+		subscribed[conn] = make(map[string]bool)
+		mux.Unlock()
+		defer func() {
+			mux.Lock()
+			delete(subscribed, conn)
+			mux.Unlock()
+		}()
+		for {
+			var message rtcMessage
+			if conn.ReadJSON(&message) != nil {
+				return
+			}
+			var command struct {
+				Command   string          `json:"command"`
+				Arguments json.RawMessage `json:"arguments"`
+			}
+			json.Unmarshal(message.Data, &command)
+			var arguments struct {
+				Namespace string          `json:"nsp"`
+				Channel   string          `json:"channel"`
+				Data      json.RawMessage `json:"data"`
+			}
+			json.Unmarshal(command.Arguments, &arguments)
+			// Events are written while locked so writes to a connection never overlap
+			mux.Lock()
+			switch command.Command {
+			case "subscribe":
+				subscribed[conn][arguments.Namespace] = true
+			case "unsubscribe":
+				delete(subscribed[conn], arguments.Namespace)
+			case "publish":
+				id++
+				event, _ := json.Marshal(rtcEventData{
+					ID:        fmt.Sprint(id),
+					Action:    "published",
+					Resource:  rtcResource{Type: "channel", Name: arguments.Channel},
+					Timestamp: time.Now(),
+					Data:      arguments.Data,
+				})
+				for subscriber, channels := range subscribed {
+					if channels[arguments.Channel] {
+						// A subscriber which has disconnected is skipped, as the realtime service would
+						subscriber.WriteJSON(rtcMessage{Type: "event", Data: event})
+					}
+				}
+			}
+			mux.Unlock()
+		}
+	})
+}
+
+func TestRPCCall(t *testing.T) {
+	server := newBrokerServer(t)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.NewRealtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rpcServer, err := realtime.Channel("maths").NewRPCServer(ctx)
+	assert.NoError(t, err)
+	defer rpcServer.Close()
+	rpcServer.Handle("add", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var numbers []int
+		if err := json.Unmarshal(params, &numbers); err != nil {
+			return nil, err
+		}
+		total := 0
+		for _, n := range numbers {
+			total += n
+		}
+		return total, nil
+	})
+	rpcServer.Handle("divide", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return nil, errors.New("division by zero")
+	})
+	served := make(chan error, 1)
+	go func() {
+		served <- rpcServer.Serve(ctx)
+	}()
+
+	rpcClient, err := realtime.Channel("maths").NewRPCClient(ctx)
+	assert.NoError(t, err)
+	defer rpcClient.Close()
+
+	// The server is subscribed once created, so calls made before Serve starts are not lost
+	var total int
+	err = rpcClient.Call(ctx, "add", []int{1, 2, 3}, &total)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, total)
+
+	err = rpcClient.Call(ctx, "divide", []int{1, 0}, nil)
+	assert.True(t, errors.Is(err, ErrCallFailed))
+	assert.Contains(t, err.Error(), "division by zero")
+
+	err = rpcClient.Call(ctx, "subtract", []int{1, 2}, nil)
+	assert.True(t, errors.Is(err, ErrCallFailed))
+	assert.Contains(t, err.Error(), "unknown method subtract")
+
+	cancel()
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve did not return once the context ended")
+	}
+}
+
+func TestRPCCallTimeout(t *testing.T) {
+	server := newBrokerServer(t)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.NewRealtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	rpcClient, err := realtime.Channel("maths").NewRPCClient(context.Background())
+	assert.NoError(t, err)
+	defer rpcClient.Close()
+	rpcClient.SetTimeout(50 * time.Millisecond)
+
+	// Nothing is serving the channel, so no response arrives
+	start := time.Now()
+	err = rpcClient.Call(context.Background(), "add", []int{1, 2}, nil)
+	assert.True(t, errors.Is(err, ErrCallTimeout))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Empty(t, rpcClient.pending)
+}
@@ -0,0 +1,35 @@
+package jexiasdkgo
+
+// Session holds the token, credentials and refresh cycle of a single user, such as one end user of a backend
+// It shares the project and http client of the client it was made from, so connections are pooled between sessions
+// As the client is embedded, everything a client can do can be done with a session, including GetDataset and GetFileset
+type Session struct {
+	*Client
+}
+
+// NewSession creates a session without a token, authenticate it with UseUMSToken or UseAPKToken
+// Changes made to the session, such as its token, do not affect the client or any other session
+func (c *Client) NewSession() *Session {
+	c.mux.Lock()
+	httpClient := c.http
+	c.mux.Unlock()
+	return &Session{
+		Client: &Client{
+			projectID:    c.projectID,
+			projectZone:  c.projectZone,
+			projectURL:   c.projectURL,
+			token:        Token{},
+			tokenRequest: nil,
+			http:         httpClient,
+			abortRefresh: make(chan bool),
+		},
+	}
+}
+
+// Close stops the refresh cycle of the session and forgets its token and secrets
+// The session can be authenticated again afterwards if needed
+func (s *Session) Close() {
+	s.StopAutoRefreshToken()
+	s.ForgetSecrets()
+	s.SetToken(Token{})
+}
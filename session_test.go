@@ -0,0 +1,109 @@
+package jexiasdkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSession(t *testing.T) {
+	httpClient := &http.Client{Timeout: 2 * time.Second}
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetHTTPClient(httpClient),
+	)
+	client.SetToken(Token{Access: "clientAccessToken"})
+
+	session := client.NewSession()
+	assert.Equal(t, client.projectID, session.projectID)
+	assert.Equal(t, client.projectZone, session.projectZone)
+	assert.Equal(t, client.projectURL, session.projectURL)
+	assert.True(t, httpClient == session.http, "the http client should be shared")
+	assert.Equal(t, Token{}, session.GetToken())
+
+	session.SetToken(Token{Access: "sessionAccessToken"})
+	assert.Equal(t, "clientAccessToken", client.GetToken().Access)
+}
+
+func TestSessionsHoldTheirOwnTokens(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/auth":
+			var request UMSTokenRequest
+			body, _ := ioutil.ReadAll(req.Body)
+			json.Unmarshal(body, &request)
+			rw.Write([]byte(fmt.Sprintf(`{"access_token":"%v-access","refresh_token":"%v-refresh"}`, request.Email, request.Email)))
+		case "/ds/test":
+			// Echo back the token used so the test can see which session made the request
+			rw.Write([]byte(fmt.Sprintf(`[{"token":%q}]`, req.Header.Get("Authorization"))))
+		}
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	alice := client.NewSession()
+	bob := client.NewSession()
+	assert.NoError(t, alice.UseUMSToken("alice", "secret"))
+	assert.NoError(t, bob.UseUMSToken("bob", "secret"))
+
+	assert.Equal(t, "alice-access", alice.GetToken().Access)
+	assert.Equal(t, "bob-access", bob.GetToken().Access)
+	assert.Equal(t, Token{}, client.GetToken())
+	assert.Nil(t, client.GetTokenRequest())
+
+	var data []map[string]string
+	assert.NoError(t, alice.GetDataset("test").Select(&data))
+	assert.Equal(t, "Bearer alice-access", data[0]["token"])
+	assert.NoError(t, bob.GetDataset("test").Select(&data))
+	assert.Equal(t, "Bearer bob-access", data[0]["token"])
+	assert.Equal(t, bob.Client, bob.GetFileset("test").GetClient())
+}
+
+func TestSessionClose(t *testing.T) {
+	var refreshed int32
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&refreshed, 1)
+		rw.Write([]byte(`{"access_token":"yourNewAccessToken","refresh_token":"yourNewRefreshToken"}`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	session := client.NewSession()
+	session.SetTokenRequest(UMSTokenRequest{Method: "ums", Email: "email", Password: "password"})
+	session.SetToken(Token{Access: "yourCurrentAccessToken", Lifetime: time.Millisecond})
+	session.AutoRefreshToken()
+	// Wait for the cycle to refresh at least once, however long the scheduler takes
+	for deadline := time.Now().Add(2 * time.Second); atomic.LoadInt32(&refreshed) == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	session.Close()
+	stoppedAt := atomic.LoadInt32(&refreshed)
+	assert.True(t, stoppedAt > 0)
+	assert.Equal(t, Token{}, session.GetToken())
+	assert.Equal(t, UMSTokenRequest{Method: "ums", Email: "email"}, session.GetTokenRequest())
+
+	// No refresh should happen once closed
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, stoppedAt, atomic.LoadInt32(&refreshed))
+}
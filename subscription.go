@@ -0,0 +1,379 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSubscriptionBuffer is the number of events held for a subscriber which is not keeping up
+const DefaultSubscriptionBuffer = 64
+
+// seenWindow is how long before the newest channel message the ids of those passed on are remembered
+// Messages can arrive out of order, such as from publishers on different nodes, so only the ids are used to spot repeats
+const seenWindow = time.Minute
+
+// OverflowPolicy decides what happens to an event when the buffer of a subscription is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the subscriber to make room, holding up every other subscription of the connection meanwhile
+	// It is only suited to subscribers which are always read, so it must be asked for with WithOverflowPolicy
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered event to make room for the new one, it is used by default
+	OverflowDropOldest
+	// OverflowDropNewest discards the new event, keeping those already buffered
+	OverflowDropNewest
+	// OverflowDisconnect ends the subscription, closing its channel, see Subscription.Err
+	OverflowDisconnect
+)
+
+// ErrSlowSubscriber is returned by Subscription.Err when the subscription was ended by OverflowDisconnect, compare using errors.Is
+var ErrSlowSubscriber = &Error{
+	ID:        "e017",
+	Message:   "Subscription was ended as the subscriber did not keep up",
+	Origin:    Internal,
+	Temporary: false,
+}
+
+// SubscribeOption allows a subscription to be configured with different options.
+type SubscribeOption func(*subscribeConfig)
+
+// subscribeConfig holds the values set by each SubscribeOption
+type subscribeConfig struct {
+	bufferSize int
+	overflow   OverflowPolicy
+}
+
+// newSubscribeConfig applies the options over the defaults
+func newSubscribeConfig(opts []SubscribeOption) subscribeConfig {
+	config := subscribeConfig{
+		bufferSize: DefaultSubscriptionBuffer,
+		overflow:   OverflowDropOldest,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	if config.bufferSize < 1 {
+		config.bufferSize = 1
+	}
+	return config
+}
+
+// WithBufferSize sets the number of events held for the subscriber before the overflow policy applies
+func WithBufferSize(size int) SubscribeOption {
+	return func(s *subscribeConfig) {
+		s.bufferSize = size
+	}
+}
+
+// WithOverflowPolicy sets what happens to an event when the buffer is full, OverflowDropOldest is used by default
+func WithOverflowPolicy(policy OverflowPolicy) SubscribeOption {
+	return func(s *subscribeConfig) {
+		s.overflow = policy
+	}
+}
+
+// Subscription reports how a subscriber is keeping up with its events
+type Subscription struct {
+	s *subscription
+}
+
+// Dropped returns the number of events discarded as the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.s.dropped)
+}
+
+// Buffered returns the number of events waiting for the subscriber to read them
+func (s *Subscription) Buffered() int {
+	return len(s.s.events)
+}
+
+// Err returns an error matching ErrSlowSubscriber if the subscription was ended by OverflowDisconnect, otherwise nil
+func (s *Subscription) Err() error {
+	if atomic.LoadUint32(&s.s.overflowed) == 1 {
+		return ErrSlowSubscriber
+	}
+	return nil
+}
+
+// subscription passes the events of a single resource on to whoever subscribed to them
+type subscription struct {
+	// dropped is first so it is aligned for atomic access on 32 bit platforms
+	dropped    uint64
+	overflowed uint32
+	rtcSubscription
+	resource rtcResource
+	overflow OverflowPolicy
+	realtime *Realtime
+	events   chan rtcEventData
+	// done is closed once unsubscribed so a blocked dispatch is released
+	done chan struct{}
+	// since is when the subscription was made, messages published before it are not replayed
+	since time.Time
+	// last is the timestamp of the newest channel message passed on, seen holds the ids of those within seenWindow of it
+	last    time.Time
+	seen    map[string]time.Time
+	seenMux sync.Mutex
+}
+
+// deliver passes the event on to the subscriber, unless it is a channel message which has already been passed on
+func (s *subscription) deliver(event rtcEventData) {
+	if s.accept(event) {
+		s.push(event)
+	}
+}
+
+// push adds the event to the buffer, applying the overflow policy if it is full
+// Only the connection reads into the buffer, so making room is never undone by another push
+func (s *subscription) push(event rtcEventData) {
+	select {
+	case s.events <- event:
+		return
+	case <-s.done:
+		return
+	default:
+	}
+
+	switch s.overflow {
+	case OverflowDropOldest:
+		for {
+			select {
+			case <-s.events:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+			select {
+			case s.events <- event:
+				return
+			default:
+			}
+		}
+	case OverflowDropNewest:
+		atomic.AddUint64(&s.dropped, 1)
+	case OverflowDisconnect:
+		atomic.AddUint64(&s.dropped, 1)
+		atomic.StoreUint32(&s.overflowed, 1)
+		s.realtime.unsubscribe(s)
+	default:
+		select {
+		case s.events <- event:
+		case <-s.done:
+		}
+	}
+}
+
+// accept records a channel message as passed on, returning false if it already was
+// Messages older than the newest are still accepted, they may have been published on a node with a slower clock
+func (s *subscription) accept(event rtcEventData) bool {
+	if event.ID == "" {
+		return true
+	}
+	s.seenMux.Lock()
+	defer s.seenMux.Unlock()
+	if _, ok := s.seen[event.ID]; ok {
+		return false
+	}
+	s.seen[event.ID] = event.Timestamp
+	if event.Timestamp.After(s.last) {
+		s.last = event.Timestamp
+		cutoff := s.last.Add(-seenWindow)
+		for id, timestamp := range s.seen {
+			if timestamp.Before(cutoff) {
+				delete(s.seen, id)
+			}
+		}
+	}
+	return true
+}
+
+// matches checks whether the event is for the resource and one of the actions of the subscription
+func (s *subscription) matches(event rtcEventData) bool {
+	if event.Resource != s.resource {
+		return false
+	}
+	for _, action := range s.Action {
+		if action == event.Action {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribe asks the realtime service for the actions of the namespace, returning the subscription the events are passed to
+// A condition is sent for the realtime service to filter the events by, it may be nil
+func (r *Realtime) subscribe(ctx context.Context, namespace string, resource rtcResource, actions []string, condition *Condition, config subscribeConfig) (*subscription, error) {
+	var cond json.RawMessage
+	if condition != nil {
+		var err error
+		cond, err = marshal(condition)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s := &subscription{
+		rtcSubscription: rtcSubscription{
+			Action:    actions,
+			Namespace: namespace,
+			Condition: cond,
+		},
+		resource: resource,
+		overflow: config.overflow,
+		realtime: r,
+		events:   make(chan rtcEventData, config.bufferSize),
+		done:     make(chan struct{}),
+		since:    time.Now(),
+		seen:     make(map[string]time.Time),
+	}
+	r.mux.Lock()
+	r.subscriptions[s] = true
+	r.mux.Unlock()
+	err := r.addFrame(ctx, s.rtcSubscription)
+	if err != nil {
+		r.removeSubscription(s)
+		return nil, err
+	}
+	return s, nil
+}
+
+// addFrame counts another subscription to each of the actions, subscribing if any of them had no subscription yet
+// The subscribe frame holds every action of the namespace and condition still in use, in case the realtime service replaces those it had
+func (r *Realtime) addFrame(ctx context.Context, subscription rtcSubscription) error {
+	// Held while sending so the frames of a namespace are never sent out of order
+	r.frameMux.Lock()
+	defer r.frameMux.Unlock()
+	key := subscription.key()
+	counts := r.frames[key]
+	if counts == nil {
+		counts = make(map[string]int)
+		r.frames[key] = counts
+	}
+	added := false
+	for _, action := range subscription.Action {
+		added = added || counts[action] == 0
+		counts[action]++
+	}
+	if !added {
+		return nil
+	}
+	err := r.command(ctx, "subscribe", rtcSubscription{
+		Action:    r.activeActions(key, subscription.Action),
+		Namespace: subscription.Namespace,
+		Condition: subscription.Condition,
+	})
+	if err != nil {
+		r.removeFrame(subscription)
+		return err
+	}
+	return nil
+}
+
+// releaseFrame counts one less subscription to each of the actions, unsubscribing from those no subscription is left for
+func (r *Realtime) releaseFrame(subscription rtcSubscription) error {
+	r.frameMux.Lock()
+	defer r.frameMux.Unlock()
+	removed := r.removeFrame(subscription)
+	if len(removed) == 0 {
+		return nil
+	}
+	select {
+	case <-r.done:
+		return nil
+	default:
+	}
+	return r.command(context.Background(), "unsubscribe", rtcSubscription{
+		Action:    removed,
+		Namespace: subscription.Namespace,
+		Condition: subscription.Condition,
+	})
+}
+
+// removeFrame takes the subscription off the count of each of its actions, returning those no subscription is left for
+// frameMux must be held
+func (r *Realtime) removeFrame(subscription rtcSubscription) []string {
+	key := subscription.key()
+	counts := r.frames[key]
+	if counts == nil {
+		return nil
+	}
+	var removed []string
+	for _, action := range subscription.Action {
+		if counts[action] == 0 {
+			continue
+		}
+		counts[action]--
+		if counts[action] == 0 {
+			delete(counts, action)
+			removed = append(removed, action)
+		}
+	}
+	if len(counts) == 0 {
+		delete(r.frames, key)
+	}
+	return removed
+}
+
+// activeActions returns the actions of the frame which are in use, those given first followed by the rest in order
+// frameMux must be held
+func (r *Realtime) activeActions(key frameKey, first []string) []string {
+	counts := r.frames[key]
+	var actions, rest []string
+	added := make(map[string]bool)
+	for _, action := range first {
+		if counts[action] > 0 && !added[action] {
+			added[action] = true
+			actions = append(actions, action)
+		}
+	}
+	for action := range counts {
+		if !added[action] {
+			rest = append(rest, action)
+		}
+	}
+	sort.Strings(rest)
+	return append(actions, rest...)
+}
+
+// relay passes each event of the subscription to send until the context ends, the subscription ends or the connection closes
+// send should give up and return false once the context ends, it unsubscribes once finished
+func (r *Realtime) relay(ctx context.Context, s *subscription, send func(event rtcEventData) bool) {
+	defer r.unsubscribe(s)
+	for {
+		select {
+		case event := <-s.events:
+			if !send(event) {
+				return
+			}
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-r.Done():
+			return
+		}
+	}
+}
+
+// unsubscribe stops the events of the subscription, it is safe to call once the connection has closed
+// The realtime service is only told about the actions no other subscription of the namespace is using
+func (r *Realtime) unsubscribe(s *subscription) error {
+	if !r.removeSubscription(s) {
+		return nil
+	}
+	return r.releaseFrame(s.rtcSubscription)
+}
+
+// removeSubscription stops passing events to the subscription, returning false if it was already removed
+func (r *Realtime) removeSubscription(s *subscription) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if !r.subscriptions[s] {
+		return false
+	}
+	delete(r.subscriptions, s)
+	close(s.done)
+	return true
+}
@@ -0,0 +1,361 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestSubscription makes a subscription on a realtime which is not connected
+func newTestSubscription(opts ...SubscribeOption) (*subscription, *Realtime) {
+	r := &Realtime{
+		subscriptions: make(map[*subscription]bool),
+		done:          make(chan struct{}),
+	}
+	// Commands are not sent once the connection is closed
+	close(r.done)
+	config := newSubscribeConfig(opts)
+	s := &subscription{
+		overflow: config.overflow,
+		realtime: r,
+		events:   make(chan rtcEventData, config.bufferSize),
+		done:     make(chan struct{}),
+		seen:     make(map[string]time.Time),
+	}
+	r.subscriptions[s] = true
+	return s, r
+}
+
+// pushEvents pushes events with the actions one to n
+func pushEvents(s *subscription, n int) {
+	for i := 1; i <= n; i++ {
+		s.push(rtcEventData{Action: fmt.Sprint(i)})
+	}
+}
+
+// bufferedActions reads the actions of every buffered event
+func bufferedActions(s *subscription) []string {
+	var actions []string
+	for len(s.events) > 0 {
+		actions = append(actions, (<-s.events).Action)
+	}
+	return actions
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	s, _ := newTestSubscription(WithBufferSize(2), WithOverflowPolicy(OverflowDropOldest))
+	pushEvents(s, 5)
+	subscription := &Subscription{s: s}
+	assert.Equal(t, uint64(3), subscription.Dropped())
+	assert.Equal(t, 2, subscription.Buffered())
+	assert.Equal(t, []string{"4", "5"}, bufferedActions(s))
+	assert.NoError(t, subscription.Err())
+}
+
+func TestOverflowDropNewest(t *testing.T) {
+	s, _ := newTestSubscription(WithBufferSize(2), WithOverflowPolicy(OverflowDropNewest))
+	pushEvents(s, 5)
+	assert.Equal(t, uint64(3), (&Subscription{s: s}).Dropped())
+	assert.Equal(t, []string{"1", "2"}, bufferedActions(s))
+}
+
+func TestOverflowDisconnect(t *testing.T) {
+	s, r := newTestSubscription(WithBufferSize(2), WithOverflowPolicy(OverflowDisconnect))
+	pushEvents(s, 4)
+	subscription := &Subscription{s: s}
+	assert.True(t, errors.Is(subscription.Err(), ErrSlowSubscriber))
+	// Only the event which overflowed is counted, the next is not delivered as the subscription has ended
+	assert.Equal(t, uint64(1), subscription.Dropped())
+	assert.Empty(t, r.subscriptions)
+	select {
+	case <-s.done:
+	default:
+		t.Fatal("The subscription was not ended")
+	}
+}
+
+func TestOverflowDefault(t *testing.T) {
+	// A subscriber which is never read must not hold up the connection
+	s, _ := newTestSubscription(WithBufferSize(2))
+	pushEvents(s, 3)
+	assert.Equal(t, uint64(1), (&Subscription{s: s}).Dropped())
+	assert.Equal(t, []string{"2", "3"}, bufferedActions(s))
+}
+
+func TestOverflowBlock(t *testing.T) {
+	s, r := newTestSubscription(WithBufferSize(1), WithOverflowPolicy(OverflowBlock))
+	pushEvents(s, 1)
+	pushed := make(chan bool)
+	go func() {
+		s.push(rtcEventData{Action: "2"})
+		pushed <- true
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("The push did not wait for room")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, "1", (<-s.events).Action)
+	<-pushed
+	assert.Equal(t, []string{"2"}, bufferedActions(s))
+
+	// Ending the subscription releases a blocked push
+	pushEvents(s, 1)
+	go func() {
+		s.push(rtcEventData{Action: "3"})
+		pushed <- true
+	}()
+	r.removeSubscription(s)
+	<-pushed
+	assert.Equal(t, uint64(0), (&Subscription{s: s}).Dropped())
+}
+
+func TestSlowSubscriberDoesNotStallConnection(t *testing.T) {
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.Equal(t, "slow", arguments.Namespace)
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.Equal(t, "fast", arguments.Namespace)
+		for i := 1; i <= 5; i++ {
+			writeEvent(t, conn, fmt.Sprintf(`{"id":"s%v","action":"published","resource":{"type":"channel","name":"slow"},"timestamp":"2020-10-01T12:00:0%vZ","data":%v}`, i, i, i))
+		}
+		writeEvent(t, conn, `{"id":"f1","action":"published","resource":{"type":"channel","name":"fast"},"timestamp":"2020-10-01T12:00:00Z","data":1}`)
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.NewRealtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	slow, err := realtime.Channel("slow").Subscribe(context.Background(), WithBufferSize(1), WithOverflowPolicy(OverflowDropOldest))
+	assert.NoError(t, err)
+	fast, err := realtime.Channel("fast").Subscribe(context.Background())
+	assert.NoError(t, err)
+
+	select {
+	case message := <-fast.Messages:
+		assert.Equal(t, "f1", message.ID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("The slow subscriber held up the connection")
+	}
+	// Depending on when the first message was read at most two are kept, the rest were dropped
+	var received []string
+	for done := false; !done; {
+		select {
+		case message := <-slow.Messages:
+			received = append(received, message.ID)
+		case <-time.After(100 * time.Millisecond):
+			done = true
+		}
+	}
+	assert.True(t, len(received) <= 2)
+	if assert.NotEmpty(t, received) {
+		assert.Equal(t, "s5", received[len(received)-1])
+	}
+	assert.Equal(t, uint64(5-len(received)), slow.Dropped())
+}
+
+func TestSubscriptionsShareFrames(t *testing.T) {
+	commands := make(chan string, 10)
+	var mux sync.Mutex
+	connections := 0
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		mux.Lock()
+		connections++
+		mux.Unlock()
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		commands <- "subscribe " + arguments.Namespace
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		commands <- "subscribe " + arguments.Namespace
+		writeEvent(t, conn, `{"action":"created","resource":{"type":"ds","name":"posts"},"data":[{"id":"1"}]}`)
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, "unsubscribe", readCommand(t, conn, &arguments))
+			commands <- "unsubscribe " + arguments.Namespace
+		}
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.Realtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	first, err := client.GetDataset("posts").Watch(firstCtx)
+	assert.NoError(t, err)
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+	second, err := client.GetDataset("posts").Watch(secondCtx)
+	assert.NoError(t, err)
+	// A subscription to other events is sent its own frame
+	comments, err := client.GetDataset("comments").Watch(secondCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, "subscribe rest api:posts", <-commands)
+	assert.Equal(t, "subscribe rest api:comments", <-commands)
+
+	// Both subscriptions receive the event of the shared frame
+	for _, watch := range []*DatasetWatch{first, second} {
+		select {
+		case event := <-watch.Events:
+			assert.Equal(t, []string{"1"}, event.RecordIDs)
+		case <-time.After(2 * time.Second):
+			t.Fatal("No event was received")
+		}
+	}
+
+	// The frame is only unsubscribed once the last subscription sharing it ends
+	cancelFirst()
+	_, open := <-first.Events
+	assert.False(t, open)
+	select {
+	case command := <-commands:
+		t.Fatalf("Sent %v while the frame was still in use", command)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancelSecond()
+	_, open = <-second.Events
+	assert.False(t, open)
+	_, open = <-comments.Events
+	assert.False(t, open)
+	unsubscribed := []string{<-commands, <-commands}
+	assert.ElementsMatch(t, []string{"unsubscribe rest api:posts", "unsubscribe rest api:comments"}, unsubscribed)
+	mux.Lock()
+	assert.Equal(t, 1, connections)
+	mux.Unlock()
+}
+
+func TestSubscriptionAcceptOutOfOrder(t *testing.T) {
+	s, _ := newTestSubscription()
+	at := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.deliver(rtcEventData{ID: "b", Action: "b", Timestamp: at})
+	// Published on a node with a slower clock, it is still passed on
+	s.deliver(rtcEventData{ID: "a", Action: "a", Timestamp: at.Add(-time.Second)})
+	// Repeats, such as those replayed after reconnecting, are not
+	s.deliver(rtcEventData{ID: "b", Action: "b", Timestamp: at})
+	s.deliver(rtcEventData{ID: "a", Action: "a", Timestamp: at.Add(-time.Second)})
+	assert.Equal(t, []string{"b", "a"}, bufferedActions(s))
+	assert.Equal(t, uint64(0), (&Subscription{s: s}).Dropped())
+
+	// Ids older than the window are forgotten so the set does not grow forever
+	s.deliver(rtcEventData{ID: "c", Action: "c", Timestamp: at.Add(2 * seenWindow)})
+	assert.Equal(t, map[string]time.Time{"c": at.Add(2 * seenWindow)}, s.seen)
+}
+
+func TestSubscriptionsShareActions(t *testing.T) {
+	// sent is a frame the server received, every frame is for the posts dataset
+	type sent struct {
+		command string
+		actions []string
+	}
+	commands := make(chan sent, 10)
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		for i := 0; i < 4; i++ {
+			var arguments rtcSubscription
+			command := readCommand(t, conn, &arguments)
+			if command == "subscribe" && len(arguments.Action) == 3 {
+				writeEvent(t, conn, `{"action":"updated","resource":{"type":"ds","name":"posts"},"data":[{"id":"1"}]}`)
+			}
+			if command == "unsubscribe" && len(arguments.Action) == 2 {
+				writeEvent(t, conn, `{"action":"created","resource":{"type":"ds","name":"posts"},"data":[{"id":"2"}]}`)
+			}
+			assert.Equal(t, "rest api:posts", arguments.Namespace)
+			commands <- sent{command, arguments.Action}
+		}
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.Realtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	narrowCtx, cancelNarrow := context.WithCancel(context.Background())
+	defer cancelNarrow()
+	narrow, err := client.GetDataset("posts").Watch(narrowCtx, ActionCreated)
+	assert.NoError(t, err)
+	assert.Equal(t, sent{"subscribe", []string{"created"}}, <-commands)
+
+	// The frame holds every action in use, including those already subscribed to
+	wideCtx, cancelWide := context.WithCancel(context.Background())
+	wide, err := client.GetDataset("posts").Watch(wideCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, sent{"subscribe", allActions}, <-commands)
+	select {
+	case event := <-wide.Events:
+		assert.Equal(t, ActionUpdated, event.Action)
+	case <-time.After(2 * time.Second):
+		t.Fatal("No event was received")
+	}
+
+	// Only the actions no other watch is using are unsubscribed, so the narrow watch still receives its events
+	cancelWide()
+	assert.Equal(t, sent{"unsubscribe", []string{"updated", "deleted"}}, <-commands)
+	select {
+	case event := <-narrow.Events:
+		assert.Equal(t, ActionCreated, event.Action)
+		assert.Equal(t, []string{"2"}, event.RecordIDs)
+	case <-time.After(2 * time.Second):
+		t.Fatal("The narrow watch stopped receiving events")
+	}
+
+	cancelNarrow()
+	assert.Equal(t, sent{"unsubscribe", []string{"created"}}, <-commands)
+}
+
+func TestSubscriptionsShareConditions(t *testing.T) {
+	commands := make(chan rtcSubscription, 10)
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		for i := 0; i < 4; i++ {
+			var arguments rtcSubscription
+			command := readCommand(t, conn, &arguments)
+			arguments.Action = append([]string{command}, arguments.Action...)
+			commands <- arguments
+		}
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+	realtime, err := client.Realtime(context.Background())
+	assert.NoError(t, err)
+	defer realtime.Close()
+
+	published := Where(Field("status").IsEqualTo("published"))
+	cond := json.RawMessage(`[{"field":"status"},"=","published"]`)
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	defer cancelFirst()
+	_, err = client.GetDataset("posts").Watch(firstCtx, ActionCreated, published)
+	assert.NoError(t, err)
+	assert.Equal(t, rtcSubscription{Action: []string{"subscribe", "created"}, Namespace: "rest api:posts", Condition: cond}, <-commands)
+
+	// Watches with the same condition share its frame, a watch without one is sent a frame of its own
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+	_, err = client.GetDataset("posts").Watch(secondCtx, ActionCreated, published)
+	assert.NoError(t, err)
+	allCtx, cancelAll := context.WithCancel(context.Background())
+	defer cancelAll()
+	_, err = client.GetDataset("posts").Watch(allCtx, ActionCreated)
+	assert.NoError(t, err)
+	assert.Equal(t, rtcSubscription{Action: []string{"subscribe", "created"}, Namespace: "rest api:posts"}, <-commands)
+
+	// The condition is sent when unsubscribing, so the service knows which frame has ended
+	cancelFirst()
+	cancelSecond()
+	assert.Equal(t, rtcSubscription{Action: []string{"unsubscribe", "created"}, Namespace: "rest api:posts", Condition: cond}, <-commands)
+	cancelAll()
+	assert.Equal(t, rtcSubscription{Action: []string{"unsubscribe", "created"}, Namespace: "rest api:posts"}, <-commands)
+}
@@ -0,0 +1,230 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	// SyncPathField is the custom field holding the relative path of a file uploaded by SyncDir
+	// It is needed as the name of a file may not keep the directories it was uploaded with
+	SyncPathField = "path"
+	// DefaultSyncConcurrency is the number of files transferred at the same time by SyncDir
+	DefaultSyncConcurrency = 4
+	// syncPageSize is the number of records fetched per request when listing the fileset
+	syncPageSize = 100
+)
+
+// SyncOption allows a directory sync to be configured with different options.
+type SyncOption func(*syncConfig)
+
+// syncConfig holds the values set by each SyncOption
+type syncConfig struct {
+	deleteOrphans bool
+	concurrency   int
+}
+
+// DeleteOrphans removes files from the fileset which no longer exist in the local directory
+func DeleteOrphans() SyncOption {
+	return func(s *syncConfig) {
+		s.deleteOrphans = true
+	}
+}
+
+// WithConcurrency sets the number of files transferred at the same time
+func WithConcurrency(concurrency int) SyncOption {
+	return func(s *syncConfig) {
+		s.concurrency = concurrency
+	}
+}
+
+// SyncReport is a summary of the changes made by SyncDir, each list holds the names of the files
+type SyncReport struct {
+	Uploaded  []string
+	Updated   []string
+	Unchanged []string
+	Deleted   []string
+	// Failed holds the error for each file which could not be synced
+	Failed map[string]error
+	mux    sync.Mutex
+}
+
+// add records the outcome of a single file
+func (r *SyncReport) add(list *[]string, name string, err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if err != nil {
+		r.Failed[name] = err
+		return
+	}
+	*list = append(*list, name)
+}
+
+// sort orders each list by name as the transfers can finish in any order
+func (r *SyncReport) sort() {
+	sort.Strings(r.Uploaded)
+	sort.Strings(r.Updated)
+	sort.Strings(r.Unchanged)
+	sort.Strings(r.Deleted)
+}
+
+// SyncDir makes the fileset match the files within the local directory, including those in sub-directories
+// Files are matched by their relative path, kept in the SyncPathField, and compared by their SHA-256, kept in the ChecksumField
+// New files are uploaded, changed files are uploaded again before the old record is deleted
+func (f *Fileset) SyncDir(ctx context.Context, localDir string, opts ...SyncOption) (*SyncReport, error) {
+	config := syncConfig{
+		concurrency: DefaultSyncConcurrency,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	if config.concurrency < 1 {
+		config.concurrency = 1
+	}
+
+	local, err := localChecksums(localDir)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := f.listAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string][]FileRecord{}
+	for _, record := range remote {
+		existing[record.Path()] = append(existing[record.Path()], record)
+	}
+
+	report := &SyncReport{Failed: map[string]error{}}
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, config.concurrency)
+	run := func(task func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case limit <- struct{}{}:
+				defer func() { <-limit }()
+				task()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	for name, checksum := range local {
+		name := name
+		records := existing[name]
+		switch {
+		case len(records) == 0:
+			run(func() {
+				report.add(&report.Uploaded, name, f.syncUpload(ctx, localDir, name))
+			})
+		case len(records) == 1 && records[0].Fields[ChecksumField] == checksum:
+			report.add(&report.Unchanged, name, nil)
+		default:
+			run(func() {
+				err := f.syncUpload(ctx, localDir, name)
+				if err == nil {
+					err = f.deleteRecords(ctx, records)
+				}
+				report.add(&report.Updated, name, err)
+			})
+		}
+	}
+
+	if config.deleteOrphans {
+		for name, records := range existing {
+			if _, ok := local[name]; ok {
+				continue
+			}
+			name, records := name, records
+			run(func() {
+				report.add(&report.Deleted, name, f.deleteRecords(ctx, records))
+			})
+		}
+	}
+
+	wg.Wait()
+	report.sort()
+	if ctx.Err() != nil {
+		return report, ctx.Err()
+	}
+	if len(report.Failed) > 0 {
+		return report, &Error{
+			ID:        "e010",
+			Message:   fmt.Errorf("Unable to sync %v of the files in %v", len(report.Failed), localDir).Error(),
+			Origin:    Internal,
+			Temporary: false,
+		}
+	}
+	return report, nil
+}
+
+// syncUpload uploads a single file from the local directory, storing its path alongside it
+func (f *Fileset) syncUpload(ctx context.Context, localDir, name string) error {
+	file, err := os.Open(filepath.Join(localDir, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	// The checksum is worked out again by Upload as the file is sent, in case it has changed since
+	_, err = f.Upload(ctx, name, file, map[string]interface{}{SyncPathField: name})
+	return err
+}
+
+// deleteRecords removes the given records and their files from the fileset
+func (f *Fileset) deleteRecords(ctx context.Context, records []FileRecord) error {
+	ids := make([]interface{}, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	_, err := f.Delete(ctx, Where(Field("id").IsInArray(ids...)))
+	return err
+}
+
+// listAll fetches every record within the fileset a page at a time, options such as Where limit the records fetched
+func (f *Fileset) listAll(ctx context.Context, opts ...QueryOption) ([]FileRecord, error) {
+	var all []FileRecord
+	for offset := 0; ; offset += syncPageSize {
+		page := append([]QueryOption{SortAsc("created_at"), Limit(syncPageSize), Offset(offset)}, opts...)
+		records, err := f.Select(ctx, page...)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, records...)
+		if len(records) < syncPageSize {
+			return all, nil
+		}
+	}
+}
+
+// localChecksums walks the directory, returning the SHA-256 of each file keyed by its slash separated relative path
+func localChecksums(localDir string) (map[string]string, error) {
+	checksums := map[string]string{}
+	err := filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return err
+		}
+		checksums[filepath.ToSlash(rel)] = checksum
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return checksums, nil
+}
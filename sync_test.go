@@ -0,0 +1,164 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncServer keeps the file records of a single fileset in memory
+type syncServer struct {
+	t       *testing.T
+	mux     sync.Mutex
+	records []map[string]interface{}
+	nextID  int
+}
+
+func (s *syncServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	assert.Equal(s.t, "/fs/test", req.URL.Path)
+	switch req.Method {
+	case http.MethodGet:
+		var rng struct {
+			Limit  int `json:"limit"`
+			Offset int `json:"offset"`
+		}
+		json.Unmarshal([]byte(req.URL.Query().Get("range")), &rng)
+		page := []map[string]interface{}{}
+		for i := rng.Offset; i < len(s.records) && i < rng.Offset+rng.Limit; i++ {
+			page = append(page, s.records[i])
+		}
+		payload, _ := json.Marshal(page)
+		rw.Write(payload)
+	case http.MethodPost:
+		assert.NoError(s.t, req.ParseMultipartForm(1024))
+		var record map[string]interface{}
+		json.Unmarshal([]byte(req.FormValue("data")), &record)
+		_, header, err := req.FormFile("file")
+		assert.NoError(s.t, err)
+		s.nextID++
+		record["id"] = fmt.Sprintf("new-%v", s.nextID)
+		record["name"] = header.Filename
+		s.records = append(s.records, record)
+		payload, _ := json.Marshal(record)
+		rw.Write(payload)
+	case http.MethodDelete:
+		var cond []interface{}
+		json.Unmarshal([]byte(req.URL.Query().Get("cond")), &cond)
+		ids := map[interface{}]bool{}
+		for _, id := range cond[2].([]interface{}) {
+			ids[id] = true
+		}
+		deleted := []map[string]interface{}{}
+		kept := []map[string]interface{}{}
+		for _, record := range s.records {
+			if ids[record["id"]] {
+				deleted = append(deleted, record)
+			} else {
+				kept = append(kept, record)
+			}
+		}
+		s.records = kept
+		payload, _ := json.Marshal(deleted)
+		rw.Write(payload)
+	}
+}
+
+func (s *syncServer) names() map[string]string {
+	names := map[string]string{}
+	for _, record := range s.records {
+		name := record["name"].(string)
+		if path, ok := record[SyncPathField].(string); ok {
+			name = path
+		}
+		names[name] = record[ChecksumField].(string)
+	}
+	return names
+}
+
+func TestFilesetSyncDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jexia")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0700))
+	files := map[string]string{
+		"new.txt":     "new",
+		"changed.txt": "changed",
+		"same.txt":    "same",
+		"sub/new.txt": "nested",
+	}
+	for name, content := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0600))
+	}
+	sameChecksum, err := fileChecksum(filepath.Join(dir, "same.txt"))
+	assert.NoError(t, err)
+
+	handler := &syncServer{t: t, records: []map[string]interface{}{
+		{"id": "changed", "name": "changed.txt", ChecksumField: "old"},
+		{"id": "same", "name": "same.txt", ChecksumField: sameChecksum},
+		{"id": "orphan", "name": "orphan.txt", ChecksumField: "old"},
+	}}
+	// Start a local HTTP server
+	server := httptest.NewServer(handler)
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	report, err := fileset.SyncDir(context.Background(), dir, DeleteOrphans(), WithConcurrency(2))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new.txt", "sub/new.txt"}, report.Uploaded)
+	assert.Equal(t, []string{"changed.txt"}, report.Updated)
+	assert.Equal(t, []string{"same.txt"}, report.Unchanged)
+	assert.Equal(t, []string{"orphan.txt"}, report.Deleted)
+	assert.Empty(t, report.Failed)
+
+	expected := map[string]string{}
+	for name := range files {
+		checksum, err := fileChecksum(filepath.Join(dir, filepath.FromSlash(name)))
+		assert.NoError(t, err)
+		expected[name] = checksum
+	}
+	assert.Equal(t, expected, handler.names())
+}
+
+func TestFilesetSyncDirKeepsOrphans(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jexia")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	handler := &syncServer{t: t, records: []map[string]interface{}{
+		{"id": "orphan", "name": "orphan.txt", ChecksumField: "old"},
+	}}
+	// Start a local HTTP server
+	server := httptest.NewServer(handler)
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	report, err := fileset.SyncDir(context.Background(), dir)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Deleted)
+	assert.Equal(t, map[string]string{"orphan.txt": "old"}, handler.names())
+}
@@ -0,0 +1,282 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidResetToken is returned by ResetPassword when the token is unknown, used or expired, compare using errors.Is
+var ErrInvalidResetToken = &Error{
+	ID:        "e012",
+	Message:   "Password reset token is not valid",
+	Origin:    API,
+	Temporary: false,
+}
+
+// ErrWrongPassword is returned by ChangePassword when the current password is not correct, compare using errors.Is
+var ErrWrongPassword = &Error{
+	ID:        "e013",
+	Message:   "Current password is not correct",
+	Origin:    API,
+	Temporary: false,
+}
+
+// User is a project user managed by Jexia's user management system (UMS)
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Fields holds any custom fields stored against the user, such as those passed to SignUpUser
+	Fields map[string]interface{} `json:"-"`
+}
+
+// userFields are the fields of a User which are not custom fields
+var userFields = []string{"id", "email", "active", "created_at", "updated_at"}
+
+// UnmarshalJSON decodes the known user fields and collects the remaining custom fields
+func (u *User) UnmarshalJSON(b []byte) error {
+	// user prevents UnmarshalJSON from being called recursively
+	type user User
+	var decoded user
+	err := json.Unmarshal(b, &decoded)
+	if err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(b, &fields)
+	if err != nil {
+		return err
+	}
+	for _, name := range userFields {
+		delete(fields, name)
+	}
+	if len(fields) > 0 {
+		decoded.Fields = fields
+	}
+	*u = User(decoded)
+	return nil
+}
+
+// MarshalJSON encodes the user with its custom fields alongside the known fields
+func (u User) MarshalJSON() ([]byte, error) {
+	// user prevents MarshalJSON from being called recursively
+	type user User
+	b, err := json.Marshal(user(u))
+	if err != nil || len(u.Fields) == 0 {
+		return b, err
+	}
+	fields := make(map[string]interface{}, len(u.Fields)+len(userFields))
+	for key, value := range u.Fields {
+		fields[key] = value
+	}
+	// The known fields take priority over custom fields of the same name
+	err = json.Unmarshal(b, &fields)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// Decode copies the user, including its custom fields, into a type of your own such as a struct with JSON tags
+func (u *User) Decode(target interface{}) error {
+	b, err := marshal(u)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, target)
+}
+
+// SignUpOption allows a sign up to be configured with different options.
+type SignUpOption func(*signUpConfig)
+
+// signUpConfig holds the values set by each SignUpOption
+type signUpConfig struct {
+	login bool
+}
+
+// WithLogin logs the new user in once signed up, so the client holds the token of that user
+func WithLogin() SignUpOption {
+	return func(s *signUpConfig) {
+		s.login = true
+	}
+}
+
+// SignUpUser creates a new project user with the email and password, the extra fields are stored against the user
+func (c *Client) SignUpUser(ctx context.Context, email, password string, extraFields map[string]interface{}, opts ...SignUpOption) (*User, error) {
+	var user User
+	config := signUpConfig{}
+	for _, o := range opts {
+		o(&config)
+	}
+
+	fields := make(map[string]interface{}, len(extraFields)+2)
+	for key, value := range extraFields {
+		fields[key] = value
+	}
+	fields["email"] = email
+	fields["password"] = password
+	payload, err := marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.post(
+		fmt.Sprintf("%v/ums/signup", c.projectURL),
+		&user,
+		setContext(ctx),
+		setBody(payload),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.login {
+		err = c.UseUMSToken(email, password)
+		if err != nil {
+			return &user, err
+		}
+	}
+	return &user, nil
+}
+
+// GetCurrentUser fetches the user the client is logged in as, see UseUMSToken
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	var user User
+	err := c.get(
+		fmt.Sprintf("%v/ums/user", c.projectURL),
+		&user,
+		setContext(ctx),
+		addToken(c.GetToken().Access),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateCurrentUser sets the custom fields of the user the client is logged in as, returning the updated user
+// The fields can be any value which marshals into a JSON object, such as a map or a struct
+func (c *Client) UpdateCurrentUser(ctx context.Context, fields interface{}) (*User, error) {
+	var user User
+	payload, err := marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	err = c.put(
+		fmt.Sprintf("%v/ums/user", c.projectURL),
+		&user,
+		setContext(ctx),
+		addToken(c.GetToken().Access),
+		setBody(payload),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteCurrentUser removes the user the client is logged in as, the password is needed to confirm the deletion
+// Once deleted the token would no longer be valid, so it is cleared along with the secrets and the refresh cycle is stopped
+func (c *Client) DeleteCurrentUser(ctx context.Context, password string) error {
+	payload, err := marshal(map[string]string{"password": password})
+	if err != nil {
+		return err
+	}
+	err = c.delete(
+		fmt.Sprintf("%v/ums/user", c.projectURL),
+		nil,
+		setContext(ctx),
+		addToken(c.GetToken().Access),
+		setHeader("Content-Type", "application/json"),
+		setBody(payload),
+	)
+	if err != nil {
+		return err
+	}
+	c.StopAutoRefreshToken()
+	c.ForgetSecrets()
+	c.SetToken(Token{})
+	return nil
+}
+
+// RequestPasswordReset asks Jexia to email a password reset token to the user with the email
+func (c *Client) RequestPasswordReset(ctx context.Context, email string) error {
+	payload, err := marshal(map[string]string{"email": email})
+	if err != nil {
+		return err
+	}
+	return c.post(
+		fmt.Sprintf("%v/ums/resetpassword", c.projectURL),
+		nil,
+		setContext(ctx),
+		setBody(payload),
+	)
+}
+
+// ResetPassword sets a new password using the token sent by RequestPasswordReset
+// An error matching ErrInvalidResetToken is returned if the token is unknown, used or expired
+// Other errors, such as a new password which does not meet the password policy, are returned as they are
+func (c *Client) ResetPassword(ctx context.Context, token, newPassword string) error {
+	payload, err := marshal(map[string]string{
+		"token":        token,
+		"new_password": newPassword,
+	})
+	if err != nil {
+		return err
+	}
+	err = c.put(
+		fmt.Sprintf("%v/ums/resetpassword", c.projectURL),
+		nil,
+		setContext(ctx),
+		setBody(payload),
+	)
+	return typedError(err, ErrInvalidResetToken, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone)
+}
+
+// ChangePassword replaces the password of the user the client is logged in as
+// An error matching ErrWrongPassword is returned if the current password is not correct
+// Other errors, such as a new password which does not meet the password policy, are returned as they are
+func (c *Client) ChangePassword(ctx context.Context, oldPassword, newPassword string) error {
+	payload, err := marshal(map[string]string{
+		"old_password": oldPassword,
+		"new_password": newPassword,
+	})
+	if err != nil {
+		return err
+	}
+	err = c.post(
+		fmt.Sprintf("%v/ums/changepassword", c.projectURL),
+		nil,
+		setContext(ctx),
+		addToken(c.GetToken().Access),
+		setBody(payload),
+	)
+	// An unauthorized response means the access token was rejected rather than the password
+	return typedError(err, ErrWrongPassword, http.StatusForbidden)
+}
+
+// typedError replaces an API error with one matching the typed error when it has one of the status codes
+// The message from the API is kept so the reason is not lost
+func typedError(err error, typed *Error, statuses ...int) error {
+	e, ok := err.(*Error)
+	if !ok || e.Origin != API {
+		return err
+	}
+	for _, status := range statuses {
+		if e.Status == status {
+			return &Error{
+				ID:        typed.ID,
+				Message:   fmt.Sprintf("%v: %v", typed.Message, e.Message),
+				Origin:    API,
+				Temporary: false,
+				Status:    e.Status,
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,299 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserUnmarshal(t *testing.T) {
+	var user User
+	err := json.Unmarshal([]byte(`{"id":"test","email":"user@example.com","active":true,"created_at":"2020-07-08T16:08:50.304789Z","updated_at":"2020-07-08T16:08:50.304789Z","nickname":"tester"}`), &user)
+	assert.NoError(t, err)
+	assert.Equal(t, User{
+		ID:        "test",
+		Email:     "user@example.com",
+		Active:    true,
+		CreatedAt: time.Date(2020, 07, 8, 16, 8, 50, 304789000, time.UTC),
+		UpdatedAt: time.Date(2020, 07, 8, 16, 8, 50, 304789000, time.UTC),
+		Fields:    map[string]interface{}{"nickname": "tester"},
+	}, user)
+}
+
+func TestSignUpUser(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/ums/signup", req.URL.String())
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, 0, len(req.Header["Authorization"]))
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.JSONEq(t, `{"email":"user@example.com","password":"secret","nickname":"tester"}`, string(body))
+		// Send response to be tested
+		rw.Write([]byte(`{"id":"test","email":"user@example.com","active":true,"nickname":"tester"}`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+
+	user, err := client.SignUpUser(context.Background(), "user@example.com", "secret", map[string]interface{}{"nickname": "tester"})
+	assert.NoError(t, err)
+	assert.Equal(t, &User{
+		ID:     "test",
+		Email:  "user@example.com",
+		Active: true,
+		Fields: map[string]interface{}{"nickname": "tester"},
+	}, user)
+	assert.Equal(t, Token{}, client.GetToken())
+}
+
+func TestSignUpUserWithLogin(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/ums/signup":
+			rw.Write([]byte(`{"id":"test","email":"user@example.com","active":true}`))
+		case "/auth":
+			body, _ := ioutil.ReadAll(req.Body)
+			assert.JSONEq(t, `{"method":"ums","email":"user@example.com","password":"secret"}`, string(body))
+			rw.Write([]byte(`{"access_token":"yourNewAccessToken","refresh_token":"yourNewRefreshToken"}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+
+	user, err := client.SignUpUser(context.Background(), "user@example.com", "secret", nil, WithLogin())
+	assert.NoError(t, err)
+	assert.Equal(t, "test", user.ID)
+	assert.Equal(t, "yourNewAccessToken", client.GetToken().Access)
+	assert.Equal(t, UMSTokenRequest{Method: "ums", Email: "user@example.com", Password: "secret"}, client.GetTokenRequest())
+}
+
+func TestUserMarshal(t *testing.T) {
+	user := User{
+		ID:     "test",
+		Email:  "user@example.com",
+		Fields: map[string]interface{}{"nickname": "tester", "id": "ignored"},
+	}
+	b, err := json.Marshal(user)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"test","email":"user@example.com","active":false,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","nickname":"tester"}`, string(b))
+}
+
+func TestUserDecode(t *testing.T) {
+	type profile struct {
+		ID       string `json:"id"`
+		Email    string `json:"email"`
+		Nickname string `json:"nickname"`
+	}
+	user := User{
+		ID:     "test",
+		Email:  "user@example.com",
+		Fields: map[string]interface{}{"nickname": "tester"},
+	}
+	var actual profile
+	err := user.Decode(&actual)
+	assert.NoError(t, err)
+	assert.Equal(t, profile{ID: "test", Email: "user@example.com", Nickname: "tester"}, actual)
+}
+
+func newCurrentUserServer(t *testing.T, method, body, response string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/ums/user", req.URL.String())
+		assert.Equal(t, method, req.Method)
+		assert.Equal(t, "Bearer yourCurrentAccessToken", req.Header.Get("Authorization"))
+		if body != "" {
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+			actual, _ := ioutil.ReadAll(req.Body)
+			assert.JSONEq(t, body, string(actual))
+		}
+		// Send response to be tested
+		rw.Write([]byte(response))
+	}))
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	server := newCurrentUserServer(t, http.MethodGet, "", `{"id":"test","email":"user@example.com","nickname":"tester"}`)
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	client.SetToken(Token{Access: "yourCurrentAccessToken"})
+
+	user, err := client.GetCurrentUser(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &User{ID: "test", Email: "user@example.com", Fields: map[string]interface{}{"nickname": "tester"}}, user)
+}
+
+func TestUpdateCurrentUser(t *testing.T) {
+	server := newCurrentUserServer(t, http.MethodPut, `{"nickname":"someone"}`, `{"id":"test","email":"user@example.com","nickname":"someone"}`)
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	client.SetToken(Token{Access: "yourCurrentAccessToken"})
+
+	user, err := client.UpdateCurrentUser(context.Background(), map[string]string{"nickname": "someone"})
+	assert.NoError(t, err)
+	assert.Equal(t, "someone", user.Fields["nickname"])
+}
+
+func TestDeleteCurrentUser(t *testing.T) {
+	server := newCurrentUserServer(t, http.MethodDelete, `{"password":"secret"}`, ``)
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	client.SetToken(Token{Access: "yourCurrentAccessToken"})
+	client.SetTokenRequest(UMSTokenRequest{Method: "ums", Email: "user@example.com", Password: "secret"})
+
+	err := client.DeleteCurrentUser(context.Background(), "secret")
+	assert.NoError(t, err)
+	// The token of the deleted user is cleared so it is not refreshed
+	assert.Equal(t, Token{}, client.GetToken())
+	assert.Equal(t, UMSTokenRequest{Method: "ums", Email: "user@example.com"}, client.GetTokenRequest())
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/ums/resetpassword", req.URL.String())
+		assert.Equal(t, http.MethodPost, req.Method)
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.JSONEq(t, `{"email":"user@example.com"}`, string(body))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+
+	err := client.RequestPasswordReset(context.Background(), "user@example.com")
+	assert.NoError(t, err)
+}
+
+func TestResetPassword(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/ums/resetpassword", req.URL.String())
+		assert.Equal(t, http.MethodPut, req.Method)
+		body, _ := ioutil.ReadAll(req.Body)
+		switch string(body) {
+		case `{"new_password":"newSecret","token":"validToken"}`:
+		case `{"new_password":"short","token":"validToken"}`:
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte(`[{"request_id":"abc","message":"password is too short"}]`))
+		default:
+			rw.WriteHeader(http.StatusGone)
+			rw.Write([]byte(`[{"request_id":"abc","message":"token expired"}]`))
+		}
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+
+	err := client.ResetPassword(context.Background(), "validToken", "newSecret")
+	assert.NoError(t, err)
+
+	err = client.ResetPassword(context.Background(), "expiredToken", "newSecret")
+	assert.True(t, errors.Is(err, ErrInvalidResetToken))
+	assert.Contains(t, err.Error(), "token expired")
+	assert.False(t, errors.Is(err, ErrWrongPassword))
+
+	// A password rejected by the policy is not reported as an invalid token
+	err = client.ResetPassword(context.Background(), "validToken", "short")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidResetToken))
+	assert.Contains(t, err.Error(), "password is too short")
+}
+
+func TestChangePassword(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/ums/changepassword", req.URL.String())
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "Bearer yourCurrentAccessToken", req.Header.Get("Authorization"))
+		body, _ := ioutil.ReadAll(req.Body)
+		switch string(body) {
+		case `{"new_password":"newSecret","old_password":"secret"}`:
+		case `{"new_password":"short","old_password":"secret"}`:
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte(`[{"request_id":"abc","message":"password is too short"}]`))
+		default:
+			rw.WriteHeader(http.StatusForbidden)
+			rw.Write([]byte(`[{"request_id":"abc","message":"wrong password"}]`))
+		}
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	client.SetToken(Token{Access: "yourCurrentAccessToken"})
+
+	err := client.ChangePassword(context.Background(), "secret", "newSecret")
+	assert.NoError(t, err)
+
+	err = client.ChangePassword(context.Background(), "notSecret", "newSecret")
+	assert.True(t, errors.Is(err, ErrWrongPassword))
+
+	err = client.ChangePassword(context.Background(), "secret", "short")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrWrongPassword))
+}
+
+func TestTypedErrorKeepsOtherErrors(t *testing.T) {
+	apiErr := &Error{ID: "abc", Message: "server error", Origin: API, Status: http.StatusInternalServerError}
+	assert.Equal(t, apiErr, typedError(apiErr, ErrWrongPassword, http.StatusForbidden))
+
+	internalErr := &Error{ID: "e005", Origin: Internal}
+	assert.Equal(t, internalErr, typedError(internalErr, ErrWrongPassword, http.StatusForbidden))
+	assert.Nil(t, typedError(nil, ErrWrongPassword, http.StatusForbidden))
+}
@@ -0,0 +1,176 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// DefaultProgressInterval is the minimum time between two progress callbacks during an upload
+const DefaultProgressInterval = 100 * time.Millisecond
+
+// ErrUploadCancelled is returned when an upload is stopped by its context, compare using errors.Is
+var ErrUploadCancelled = &Error{
+	ID:        "e009",
+	Message:   "Upload cancelled",
+	Origin:    Internal,
+	Temporary: false,
+}
+
+// UploadProgress describes how far an upload has got when passed to a progress callback
+type UploadProgress struct {
+	// Sent is the number of bytes of the file sent so far
+	Sent int64
+	// Total is the size of the file in bytes, or -1 if it is not known
+	Total int64
+	// Rate is the average number of bytes sent per second since the upload started
+	Rate float64
+}
+
+// UploadOption allows an upload to be configured with different options.
+type UploadOption func(*uploadConfig)
+
+// uploadConfig holds the values set by each UploadOption
+type uploadConfig struct {
+	progress         func(UploadProgress)
+	progressInterval time.Duration
+	size             int64
+	contentType      string
+	// Only used by chunked uploads
+	partSize    int64
+	partRetries int
+	partTimeout time.Duration
+	resumeFile  string
+}
+
+// WithProgress calls the function as the file is sent, at most once per DefaultProgressInterval and once more when finished
+// The function is called from the goroutine writing the request body, so it should not block for long
+func WithProgress(progress func(UploadProgress)) UploadOption {
+	return func(u *uploadConfig) {
+		u.progress = progress
+	}
+}
+
+// WithProgressInterval changes the minimum time between two progress callbacks
+func WithProgressInterval(interval time.Duration) UploadOption {
+	return func(u *uploadConfig) {
+		u.progressInterval = interval
+	}
+}
+
+// WithSize sets the total size of the file reported in progress callbacks
+// This is only needed when the size can not be found from the reader itself, such as with a network stream
+func WithSize(size int64) UploadOption {
+	return func(u *uploadConfig) {
+		u.size = size
+	}
+}
+
+// newUploadConfig applies the options over the defaults, working out the size of the file where possible
+func newUploadConfig(file io.Reader, opts []UploadOption) uploadConfig {
+	config := uploadConfig{
+		progressInterval: DefaultProgressInterval,
+		size:             readerSize(file),
+		partSize:         DefaultPartSize,
+		partRetries:      DefaultPartRetries,
+		partTimeout:      DefaultPartTimeout,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	if config.partSize < 1 {
+		config.partSize = DefaultPartSize
+	}
+	return config
+}
+
+// readerSize returns the number of bytes left in the reader, or -1 if this can not be known
+func readerSize(file io.Reader) int64 {
+	switch r := file.(type) {
+	// Covers bytes.Reader, bytes.Buffer and strings.Reader
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	default:
+		return -1
+	}
+}
+
+// progressReader counts the bytes read from the file, reporting progress and stopping early if the context ends
+type progressReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	config   uploadConfig
+	sent     int64
+	started  time.Time
+	reported time.Time
+}
+
+func newProgressReader(ctx context.Context, reader io.Reader, config uploadConfig) *progressReader {
+	return &progressReader{
+		ctx:     ctx,
+		reader:  reader,
+		config:  config,
+		started: time.Now(),
+	}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	// Stop reading the file as soon as the upload is cancelled rather than waiting on the http client
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.reader.Read(b)
+	p.sent += int64(n)
+	if err == io.EOF {
+		p.report(true)
+	} else {
+		p.report(false)
+	}
+	return n, err
+}
+
+// report calls the progress callback if one is set and enough time has passed since the last call
+func (p *progressReader) report(final bool) {
+	if p.config.progress == nil {
+		return
+	}
+	now := time.Now()
+	if !final && now.Sub(p.reported) < p.config.progressInterval {
+		return
+	}
+	p.reported = now
+
+	rate := 0.0
+	if elapsed := now.Sub(p.started).Seconds(); elapsed > 0 {
+		rate = float64(p.sent) / elapsed
+	}
+	p.config.progress(UploadProgress{
+		Sent:  p.sent,
+		Total: p.config.size,
+		Rate:  rate,
+	})
+}
+
+// cancelledError converts the error of a request into ErrUploadCancelled if the context has ended
+func cancelledError(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	return &Error{
+		ID:        ErrUploadCancelled.ID,
+		Message:   ErrUploadCancelled.Message + ": " + ctx.Err().Error(),
+		Origin:    Internal,
+		Temporary: false,
+	}
+}
@@ -0,0 +1,133 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// endlessReader is a file which never ends, so an upload of it can only finish by being cancelled
+type endlessReader struct{}
+
+func (endlessReader) Read(b []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	return copy(b, "data"), nil
+}
+
+func TestReaderSize(t *testing.T) {
+	assert.Equal(t, int64(11), readerSize(strings.NewReader("hello world")))
+	assert.Equal(t, int64(-1), readerSize(ioutil.NopCloser(strings.NewReader("hello world"))))
+
+	file, err := ioutil.TempFile("", "jexia")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+	file.WriteString("hello world")
+	file.Seek(6, io.SeekStart)
+	assert.Equal(t, int64(5), readerSize(file))
+}
+
+func TestFilesetUploadProgress(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		rw.Write([]byte(`{"id":"test","name":"hello.txt","size":11}`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	var updates []UploadProgress
+	_, err := fileset.Upload(
+		context.Background(),
+		"hello.txt",
+		strings.NewReader("hello world"),
+		nil,
+		WithProgress(func(progress UploadProgress) {
+			updates = append(updates, progress)
+		}),
+	)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, updates)
+	last := updates[len(updates)-1]
+	assert.Equal(t, int64(11), last.Sent)
+	assert.Equal(t, int64(11), last.Total)
+	assert.True(t, last.Rate > 0)
+}
+
+func TestFilesetUploadProgressUnknownSize(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		rw.Write([]byte(`{"id":"test"}`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	var last UploadProgress
+	_, err := fileset.Upload(
+		context.Background(),
+		"hello.txt",
+		ioutil.NopCloser(strings.NewReader("hello world")),
+		nil,
+		WithProgress(func(progress UploadProgress) {
+			last = progress
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), last.Sent)
+	assert.Equal(t, int64(-1), last.Total)
+}
+
+func TestFilesetUploadCancelled(t *testing.T) {
+	received := make(chan bool)
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Read part of the body so the upload is in progress, then wait for it to be cancelled
+		req.Body.Read(make([]byte, 10))
+		close(received)
+		// Reading stops with an error once the client closes the connection
+		ioutil.ReadAll(req.Body)
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(server.URL),
+	)
+	fileset := client.GetFileset("test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-received
+		cancel()
+	}()
+
+	record, err := fileset.Upload(ctx, "endless.txt", endlessReader{}, nil)
+	assert.Nil(t, record)
+	assert.True(t, errors.Is(err, ErrUploadCancelled))
+}
@@ -0,0 +1,92 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListUsers fetches the project users, this needs the client to be authenticated with UseAPKToken
+// Options such as Where, SortAsc and Limit can be passed to filter, sort and page the users
+func (c *Client) ListUsers(ctx context.Context, opts ...QueryOption) ([]User, error) {
+	var users []User
+	query, err := buildQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	err = c.get(
+		fmt.Sprintf("%v/ums/users%v", c.projectURL, query),
+		&users,
+		setContext(ctx),
+		addToken(c.GetToken().Access),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UpdateUsers sets the fields of the project users which match the options, returning the updated users
+// The fields can be any value which marshals into a JSON object, such as a map or a struct
+// A Where option is required, otherwise ErrConditionRequired is returned rather than updating every user
+func (c *Client) UpdateUsers(ctx context.Context, fields interface{}, opts ...QueryOption) ([]User, error) {
+	var users []User
+	err := requireCondition(opts)
+	if err != nil {
+		return nil, err
+	}
+	query, err := buildQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	err = c.put(
+		fmt.Sprintf("%v/ums/users%v", c.projectURL, query),
+		&users,
+		setContext(ctx),
+		addToken(c.GetToken().Access),
+		setBody(payload),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// SuspendUsers deactivates the project users which match the options so they can no longer log in
+// A Where option is required, otherwise ErrConditionRequired is returned rather than suspending every user
+func (c *Client) SuspendUsers(ctx context.Context, opts ...QueryOption) ([]User, error) {
+	return c.UpdateUsers(ctx, map[string]bool{"active": false}, opts...)
+}
+
+// ActivateUsers reactivates the project users which match the options, such as those suspended by SuspendUsers
+// A Where option is required, otherwise ErrConditionRequired is returned
+func (c *Client) ActivateUsers(ctx context.Context, opts ...QueryOption) ([]User, error) {
+	return c.UpdateUsers(ctx, map[string]bool{"active": true}, opts...)
+}
+
+// DeleteUsers removes the project users which match the options, returning the deleted users
+// A Where option is required, otherwise ErrConditionRequired is returned rather than deleting every user
+func (c *Client) DeleteUsers(ctx context.Context, opts ...QueryOption) ([]User, error) {
+	var users []User
+	err := requireCondition(opts)
+	if err != nil {
+		return nil, err
+	}
+	query, err := buildQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	err = c.delete(
+		fmt.Sprintf("%v/ums/users%v", c.projectURL, query),
+		&users,
+		setContext(ctx),
+		addToken(c.GetToken().Access),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,124 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newUsersServer checks each request to the users endpoint before sending the response
+func newUsersServer(t *testing.T, method, cond, body, response string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/ums/users", req.URL.Path)
+		assert.Equal(t, method, req.Method)
+		assert.Equal(t, "Bearer yourAPKAccessToken", req.Header.Get("Authorization"))
+		assert.Equal(t, cond, req.URL.Query().Get("cond"))
+		if body != "" {
+			actual, _ := ioutil.ReadAll(req.Body)
+			assert.JSONEq(t, body, string(actual))
+		}
+		// Send response to be tested
+		rw.Write([]byte(response))
+	}))
+}
+
+func newUsersClient(url string) *Client {
+	client := NewClient(
+		"projectID",
+		"projectZone",
+		SetProjectURL(url),
+	)
+	client.SetToken(Token{Access: "yourAPKAccessToken"})
+	return client
+}
+
+func TestListUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Test request parameters
+		assert.Equal(t, "/ums/users", req.URL.Path)
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, `[{"field":"email"},"like","%@example.com"]`, req.URL.Query().Get("cond"))
+		assert.Equal(t, `{"limit":2,"offset":2}`, req.URL.Query().Get("range"))
+		// Send response to be tested
+		rw.Write([]byte(`[{"id":"3","email":"c@example.com","active":true},{"id":"4","email":"d@example.com","active":false}]`))
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	users, err := client.ListUsers(context.Background(), Where(Field("email").IsLike("%@example.com")), Limit(2), Offset(2))
+	assert.NoError(t, err)
+	assert.Equal(t, []User{
+		{ID: "3", Email: "c@example.com", Active: true},
+		{ID: "4", Email: "d@example.com", Active: false},
+	}, users)
+}
+
+func TestUpdateUsers(t *testing.T) {
+	server := newUsersServer(t, http.MethodPut, `[{"field":"id"},"=","3"]`, `{"plan":"pro"}`, `[{"id":"3","email":"c@example.com","plan":"pro"}]`)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	users, err := client.UpdateUsers(context.Background(), map[string]string{"plan": "pro"}, Where(Field("id").IsEqualTo("3")))
+	assert.NoError(t, err)
+	assert.Equal(t, "pro", users[0].Fields["plan"])
+}
+
+func TestSuspendUsers(t *testing.T) {
+	server := newUsersServer(t, http.MethodPut, `[{"field":"id"},"=","3"]`, `{"active":false}`, `[{"id":"3","active":false}]`)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	users, err := client.SuspendUsers(context.Background(), Where(Field("id").IsEqualTo("3")))
+	assert.NoError(t, err)
+	assert.False(t, users[0].Active)
+}
+
+func TestActivateUsers(t *testing.T) {
+	server := newUsersServer(t, http.MethodPut, `[{"field":"id"},"=","3"]`, `{"active":true}`, `[{"id":"3","active":true}]`)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	users, err := client.ActivateUsers(context.Background(), Where(Field("id").IsEqualTo("3")))
+	assert.NoError(t, err)
+	assert.True(t, users[0].Active)
+}
+
+func TestDeleteUsers(t *testing.T) {
+	server := newUsersServer(t, http.MethodDelete, `[{"field":"active"},"=",false]`, "", `[{"id":"4","email":"d@example.com"}]`)
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	users, err := client.DeleteUsers(context.Background(), Where(Field("active").IsEqualTo(false)))
+	assert.NoError(t, err)
+	assert.Equal(t, []User{{ID: "4", Email: "d@example.com"}}, users)
+}
+
+func TestUsersRequireCondition(t *testing.T) {
+	// Start a local HTTP server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Error("No request should be sent without a condition")
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+	client := newUsersClient(server.URL)
+
+	_, err := client.UpdateUsers(context.Background(), map[string]string{"plan": "pro"})
+	assert.True(t, errors.Is(err, ErrConditionRequired))
+	_, err = client.SuspendUsers(context.Background(), Limit(1))
+	assert.True(t, errors.Is(err, ErrConditionRequired))
+	_, err = client.ActivateUsers(context.Background())
+	assert.True(t, errors.Is(err, ErrConditionRequired))
+	_, err = client.DeleteUsers(context.Background())
+	assert.True(t, errors.Is(err, ErrConditionRequired))
+}
@@ -0,0 +1,201 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Action is a kind of change made to records, passing actions to Watch limits the events to those actions
+type Action string
+
+const (
+	// ActionCreated is the action of an event sent when records are inserted
+	ActionCreated Action = "created"
+	// ActionUpdated is the action of an event sent when records are changed
+	ActionUpdated Action = "updated"
+	// ActionDeleted is the action of an event sent when records are removed
+	ActionDeleted Action = "deleted"
+)
+
+// matchLookupTimeout is the longest a watch waits to look up whether the records of an event match its condition
+const matchLookupTimeout = 5 * time.Second
+
+// allActions are watched when no actions are passed to Watch
+var allActions = []string{string(ActionCreated), string(ActionUpdated), string(ActionDeleted)}
+
+// WatchOption allows a watch to be configured, an Action, a SubscribeOption and Where can be passed to Watch
+type WatchOption interface {
+	applyWatch(*watchConfig)
+}
+
+// watchConfig holds the values set by each WatchOption
+type watchConfig struct {
+	actions   []string
+	condition *Condition
+	subscribe []SubscribeOption
+}
+
+// applyWatch adds the action to those being watched
+func (a Action) applyWatch(w *watchConfig) {
+	w.actions = append(w.actions, string(a))
+}
+
+// applyWatch allows buffering options to be passed to Watch
+func (o SubscribeOption) applyWatch(w *watchConfig) {
+	w.subscribe = append(w.subscribe, o)
+}
+
+// applyWatch allows Where to limit the events to records matching its condition, other query options do not apply to a watch
+func (o QueryOption) applyWatch(w *watchConfig) {
+	q := query{}
+	o(&q)
+	if q.condition != nil {
+		w.condition = q.condition
+	}
+}
+
+// newWatchConfig applies the options, watching the default actions if none are passed
+func newWatchConfig(opts []WatchOption, defaultActions []string) watchConfig {
+	config := watchConfig{}
+	for _, o := range opts {
+		o.applyWatch(&config)
+	}
+	if len(config.actions) == 0 {
+		config.actions = defaultActions
+	}
+	return config
+}
+
+// DatasetEvent is a change made to the records of a dataset, received from the realtime service
+type DatasetEvent struct {
+	Action  Action
+	Dataset string
+	// RecordIDs are the ids of every record the action was applied to
+	RecordIDs []string
+	// ModifierID is the id of the user or API key which made the change
+	ModifierID string
+	Timestamp  time.Time
+}
+
+// DatasetWatch receives the changes made to a dataset
+type DatasetWatch struct {
+	*Subscription
+	// Events receives each change in the order it was made, it is closed once the watch ends
+	Events <-chan DatasetEvent
+}
+
+// Watch subscribes to the changes made to the dataset, pass actions such as ActionCreated to limit the events, otherwise every action is watched
+// Passing Where limits the events to records matching the condition, the same as it would for Select
+// The realtime service is asked to filter by the condition, watches with the same condition share a subscription
+// Events are delivered until the context ends, at which point it unsubscribes and closes the channel of events
+// The channel is also closed if the realtime connection fails, so a closed channel does not always mean the context has ended
+// Watches share the realtime connection of the client, which is closed once the last of them ends, see SetRealtimeOptions to configure it
+func (d *Dataset) Watch(ctx context.Context, opts ...WatchOption) (*DatasetWatch, error) {
+	config := newWatchConfig(opts, allActions)
+	realtime, err := d.GetClient().acquireRealtime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := realtime.subscribe(
+		ctx,
+		fmt.Sprintf("rest api:%v", d.GetName()),
+		rtcResource{Type: "ds", Name: d.GetName()},
+		config.actions,
+		config.condition,
+		newSubscribeConfig(config.subscribe),
+	)
+	if err != nil {
+		d.GetClient().releaseRealtime(realtime)
+		return nil, err
+	}
+
+	events := make(chan DatasetEvent)
+	go func() {
+		defer close(events)
+		// Released once unsubscribed, so the connection is not closed while the unsubscribe is sent
+		defer d.GetClient().releaseRealtime(realtime)
+		realtime.relay(ctx, s, func(received rtcEventData) bool {
+			event := newDatasetEvent(received)
+			if config.condition != nil {
+				event.RecordIDs = d.matchingRecords(ctx, received, config.condition)
+				if len(event.RecordIDs) == 0 {
+					return true
+				}
+			}
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return &DatasetWatch{
+		Subscription: &Subscription{s: s},
+		Events:       events,
+	}, nil
+}
+
+// newDatasetEvent converts an event from the realtime service into a DatasetEvent
+func newDatasetEvent(received rtcEventData) DatasetEvent {
+	event := DatasetEvent{
+		Action:     Action(received.Action),
+		Dataset:    received.Resource.Name,
+		ModifierID: received.Modifier.ID,
+		Timestamp:  received.Timestamp,
+	}
+	var records []struct {
+		ID string `json:"id"`
+	}
+	// Events without records, such as those the service does not include data for, are still passed on
+	json.Unmarshal(received.Data, &records)
+	for _, record := range records {
+		event.RecordIDs = append(event.RecordIDs, record.ID)
+	}
+	return event
+}
+
+// matchingRecords returns the ids of the records of the event which match the condition
+// The realtime service is asked to filter by the condition too, but not every service does so each record is checked here
+// Records are checked against the data sent with the event, records without the fields needed are looked up in the dataset
+// Deleted records can not be looked up, so those and any which fail to be looked up are kept rather than silently dropped
+// The lookup ends with the watch, and is given up after matchLookupTimeout so later events are not held up for long
+func (d *Dataset) matchingRecords(ctx context.Context, received rtcEventData, condition *Condition) []string {
+	var records []map[string]interface{}
+	json.Unmarshal(received.Data, &records)
+
+	var matched, unknown []string
+	for _, record := range records {
+		id, _ := record["id"].(string)
+		isMatch, ok := condition.evaluate(record)
+		switch {
+		case !ok:
+			unknown = append(unknown, id)
+		case isMatch:
+			matched = append(matched, id)
+		}
+	}
+	if len(unknown) == 0 || Action(received.Action) == ActionDeleted {
+		return append(matched, unknown...)
+	}
+
+	ids := make([]interface{}, len(unknown))
+	for i, id := range unknown {
+		ids[i] = id
+	}
+	var found []struct {
+		ID string `json:"id"`
+	}
+	ctx, cancel := context.WithTimeout(ctx, matchLookupTimeout)
+	defer cancel()
+	err := d.SelectContext(ctx, &found, Where(Field("id").IsInArray(ids...).And(condition)), Outputs("id"))
+	if err != nil {
+		return append(matched, unknown...)
+	}
+	for _, record := range found {
+		matched = append(matched, record.ID)
+	}
+	return matched
+}
@@ -0,0 +1,229 @@
+package jexiasdkgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newWebsocketServer upgrades every request and passes the connection to handle
+func newWebsocketServer(t *testing.T, handle func(conn *websocket.Conn)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}))
+}
+
+// readCommand reads the next command sent by the client
+func readCommand(t *testing.T, conn *websocket.Conn, arguments interface{}) string {
+	var message rtcMessage
+	if !assert.NoError(t, conn.ReadJSON(&message)) {
+		return ""
+	}
+	assert.Equal(t, "command", message.Type)
+	var command struct {
+		Command   string          `json:"command"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	assert.NoError(t, json.Unmarshal(message.Data, &command))
+	assert.NoError(t, json.Unmarshal(command.Arguments, arguments))
+	return command.Command
+}
+
+// writeEvent sends an event to the client
+func writeEvent(t *testing.T, conn *websocket.Conn, event string) {
+	assert.NoError(t, conn.WriteJSON(rtcMessage{Type: "event", Data: json.RawMessage(event)}))
+}
+
+func TestDatasetWatch(t *testing.T) {
+	unsubscribed := make(chan rtcSubscription, 1)
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.Equal(t, rtcSubscription{Action: []string{"created", "deleted"}, Namespace: "rest api:posts"}, arguments)
+
+		// Events for other datasets and actions are not passed on
+		writeEvent(t, conn, `{"action":"created","resource":{"type":"ds","name":"comments"},"data":[{"id":"9"}]}`)
+		writeEvent(t, conn, `{"action":"updated","resource":{"type":"ds","name":"posts"},"data":[{"id":"8"}]}`)
+		writeEvent(t, conn, `{"action":"created","resource":{"type":"ds","name":"posts"},"modifier":{"id":"user"},"timestamp":"2020-10-01T12:00:00Z","data":[{"id":"1"},{"id":"2"}]}`)
+
+		assert.Equal(t, "unsubscribe", readCommand(t, conn, &arguments))
+		unsubscribed <- arguments
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watch, err := client.GetDataset("posts").Watch(ctx, ActionCreated, ActionDeleted)
+	assert.NoError(t, err)
+
+	select {
+	case event := <-watch.Events:
+		assert.Equal(t, DatasetEvent{
+			Action:     ActionCreated,
+			Dataset:    "posts",
+			RecordIDs:  []string{"1", "2"},
+			ModifierID: "user",
+			Timestamp:  time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC),
+		}, event)
+	case <-time.After(2 * time.Second):
+		t.Fatal("No event was received")
+	}
+
+	cancel()
+	select {
+	case arguments := <-unsubscribed:
+		assert.Equal(t, "rest api:posts", arguments.Namespace)
+	case <-time.After(2 * time.Second):
+		t.Fatal("The watch did not unsubscribe")
+	}
+	_, open := <-watch.Events
+	assert.False(t, open)
+}
+
+func TestDatasetWatchReconnects(t *testing.T) {
+	var mux sync.Mutex
+	connections := 0
+	server := newWebsocketServer(t, func(conn *websocket.Conn) {
+		mux.Lock()
+		connections++
+		connection := connections
+		mux.Unlock()
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.Equal(t, allActions, arguments.Action)
+		if connection == 1 {
+			// Drop the first connection once subscribed
+			return
+		}
+		writeEvent(t, conn, `{"action":"deleted","resource":{"type":"ds","name":"posts"},"data":[{"id":"1"}]}`)
+		conn.ReadMessage()
+	})
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch, err := client.GetDataset("posts").Watch(ctx)
+	assert.NoError(t, err)
+	select {
+	case event := <-watch.Events:
+		assert.Equal(t, ActionDeleted, event.Action)
+		assert.Equal(t, []string{"1"}, event.RecordIDs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("No event was received after reconnecting")
+	}
+}
+
+func TestDatasetWatchWhere(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/ds/posts" {
+			// Only records the event did not include the fields for are looked up
+			assert.Equal(t, `[{"field":"id"},"in",["3"],"and",{"field":"status"},"=","published"]`, req.URL.Query().Get("cond"))
+			assert.Equal(t, `["id"]`, req.URL.Query().Get("outputs"))
+			rw.Write([]byte(`[{"id":"3"}]`))
+			return
+		}
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		var arguments struct {
+			Condition json.RawMessage `json:"cond"`
+		}
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		assert.JSONEq(t, `[{"field":"status"},"=","published"]`, string(arguments.Condition))
+
+		writeEvent(t, conn, `{"action":"updated","resource":{"type":"ds","name":"posts"},"data":[{"id":"1","status":"published"},{"id":"2","status":"draft"},{"id":"3"}]}`)
+		writeEvent(t, conn, `{"action":"updated","resource":{"type":"ds","name":"posts"},"data":[{"id":"2","status":"draft"}]}`)
+		writeEvent(t, conn, `{"action":"deleted","resource":{"type":"ds","name":"posts"},"data":[{"id":"4"}]}`)
+		conn.ReadMessage()
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch, err := client.GetDataset("posts").Watch(ctx, Where(Field("status").IsEqualTo("published")))
+	assert.NoError(t, err)
+
+	var events []DatasetEvent
+	for len(events) < 2 {
+		select {
+		case event := <-watch.Events:
+			events = append(events, event)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Only received %v", events)
+		}
+	}
+	assert.Equal(t, []string{"1", "3"}, events[0].RecordIDs)
+	// Deleted records can not be checked so are passed on, the event with only a draft is not
+	assert.Equal(t, ActionDeleted, events[1].Action)
+	assert.Equal(t, []string{"4"}, events[1].RecordIDs)
+}
+
+func TestDatasetWatchWhereLookupEndsWithWatch(t *testing.T) {
+	lookup := make(chan bool)
+	lookupEnded := make(chan bool, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/ds/posts" {
+			// The lookup never answers, so only the watch ending can stop it
+			lookup <- true
+			<-req.Context().Done()
+			lookupEnded <- true
+			return
+		}
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		var arguments rtcSubscription
+		assert.Equal(t, "subscribe", readCommand(t, conn, &arguments))
+		writeEvent(t, conn, `{"action":"updated","resource":{"type":"ds","name":"posts"},"data":[{"id":"1"}]}`)
+		conn.ReadMessage()
+	}))
+	// Close the server when test finishes
+	defer server.Close()
+	client := newRealtimeClient(server.URL, "yourAccessToken")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch, err := client.GetDataset("posts").Watch(ctx, Where(Field("status").IsEqualTo("published")))
+	assert.NoError(t, err)
+	select {
+	case <-lookup:
+	case <-time.After(2 * time.Second):
+		t.Fatal("The record was not looked up")
+	}
+
+	cancel()
+	select {
+	case <-lookupEnded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("The lookup was not cancelled with the watch")
+	}
+	select {
+	case _, open := <-watch.Events:
+		assert.False(t, open)
+	case <-time.After(2 * time.Second):
+		t.Fatal("The watch did not end")
+	}
+}